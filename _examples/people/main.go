@@ -7,6 +7,8 @@ import (
 	"flag"
 	"log"
 	"os"
+
+	"github.com/parsyl/parquet"
 )
 
 var (
@@ -30,7 +32,7 @@ func write() {
 
 	defer f.Close()
 
-	w, err := NewParquetWriter(f, MaxPageSize(100))
+	w, err := NewParquetWriter(f, parquet.MaxPageSize(100))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,174 @@
+package parquet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+var timeUnits = map[string]*sch.TimeUnit{
+	"MILLIS": {MILLIS: &sch.MilliSeconds{}},
+	"MICROS": {MICROS: &sch.MicroSeconds{}},
+	"NANOS":  {NANOS: &sch.NanoSeconds{}},
+}
+
+// Annotation is the logical type and the converted type (which
+// older readers use instead) of a column or of a group in its path.
+type Annotation struct {
+	LogicalType   *sch.LogicalType
+	ConvertedType *sch.ConvertedType
+}
+
+// ParseAnnotation parses an annotation in the syntax of a parquet
+// schema without the parentheses around it, for example STRING,
+// DECIMAL(9,2) or a converted type like UTF8.
+func ParseAnnotation(s string) (Annotation, error) {
+	name, rest, ok := strings.Cut(s, "(")
+	var args []string
+	if ok {
+		rest, ok = strings.CutSuffix(rest, ")")
+		if !ok {
+			return Annotation{}, fmt.Errorf("invalid annotation %s", s)
+		}
+
+		for _, a := range strings.Split(rest, ",") {
+			args = append(args, strings.TrimSpace(a))
+		}
+	}
+	return NewAnnotation(strings.TrimSpace(name), args...)
+}
+
+// NewAnnotation returns the annotation with the given name (a logical
+// or converted type) and arguments (for example DECIMAL, "9", "2").  It
+// sets both the logical type and, if there is one, the converted type.
+func NewAnnotation(name string, args ...string) (Annotation, error) {
+	name = strings.ToUpper(name)
+	argc := map[string]int{"DECIMAL": 2, "TIME": 2, "TIMESTAMP": 2, "INTEGER": 2}[name]
+	if len(args) != argc {
+		return Annotation{}, fmt.Errorf("expected %d arguments, found %d", argc, len(args))
+	}
+
+	var a Annotation
+	ct := func(c sch.ConvertedType) *sch.ConvertedType { return &c }
+	lt := &sch.LogicalType{}
+	switch name {
+	case "STRING", "UTF8":
+		lt.STRING = &sch.StringType{}
+		a.ConvertedType = ct(sch.ConvertedType_UTF8)
+	case "MAP":
+		lt.MAP = &sch.MapType{}
+		a.ConvertedType = ct(sch.ConvertedType_MAP)
+	case "MAP_KEY_VALUE":
+		lt = nil
+		a.ConvertedType = ct(sch.ConvertedType_MAP_KEY_VALUE)
+	case "LIST":
+		lt.LIST = &sch.ListType{}
+		a.ConvertedType = ct(sch.ConvertedType_LIST)
+	case "ENUM":
+		lt.ENUM = &sch.EnumType{}
+		a.ConvertedType = ct(sch.ConvertedType_ENUM)
+	case "DECIMAL":
+		precision, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil {
+			return Annotation{}, fmt.Errorf("invalid precision %q", args[0])
+		}
+
+		scale, err := strconv.ParseInt(args[1], 10, 32)
+		if err != nil {
+			return Annotation{}, fmt.Errorf("invalid scale %q", args[1])
+		}
+
+		lt.DECIMAL = &sch.DecimalType{Precision: int32(precision), Scale: int32(scale)}
+		a.ConvertedType = ct(sch.ConvertedType_DECIMAL)
+	case "DATE":
+		lt.DATE = &sch.DateType{}
+		a.ConvertedType = ct(sch.ConvertedType_DATE)
+	case "TIME", "TIMESTAMP":
+		unit, ok := timeUnits[strings.ToUpper(args[0])]
+		if !ok {
+			return Annotation{}, fmt.Errorf("invalid unit %q", args[0])
+		}
+
+		utc, err := strconv.ParseBool(args[1])
+		if err != nil {
+			return Annotation{}, fmt.Errorf("invalid isAdjustedToUTC %q", args[1])
+		}
+
+		if name == "TIME" {
+			lt.TIME = &sch.TimeType{IsAdjustedToUTC: utc, Unit: unit}
+		} else {
+			lt.TIMESTAMP = &sch.TimestampType{IsAdjustedToUTC: utc, Unit: unit}
+		}
+
+		// only UTC times in millis and micros have a converted type
+		if c, err := sch.ConvertedTypeFromString(fmt.Sprintf("%s_%s", name, strings.ToUpper(args[0]))); err == nil && utc {
+			a.ConvertedType = &c
+		}
+	case "TIME_MILLIS", "TIME_MICROS", "TIMESTAMP_MILLIS", "TIMESTAMP_MICROS":
+		i := strings.LastIndex(name, "_")
+		return NewAnnotation(name[:i], name[i+1:], "true")
+	case "INTEGER":
+		bits, err := strconv.ParseInt(args[0], 10, 8)
+		if err != nil || (bits != 8 && bits != 16 && bits != 32 && bits != 64) {
+			return Annotation{}, fmt.Errorf("invalid bit width %q", args[0])
+		}
+
+		signed, err := strconv.ParseBool(args[1])
+		if err != nil {
+			return Annotation{}, fmt.Errorf("invalid isSigned %q", args[1])
+		}
+
+		lt.INTEGER = &sch.IntType{BitWidth: int8(bits), IsSigned: signed}
+		prefix := "UINT"
+		if signed {
+			prefix = "INT"
+		}
+		c, _ := sch.ConvertedTypeFromString(fmt.Sprintf("%s_%d", prefix, bits))
+		a.ConvertedType = &c
+	case "INT_8", "INT_16", "INT_32", "INT_64", "UINT_8", "UINT_16", "UINT_32", "UINT_64":
+		i := strings.LastIndex(name, "_")
+		return NewAnnotation("INTEGER", name[i+1:], strconv.FormatBool(name[0] == 'I'))
+	case "UNKNOWN":
+		lt.UNKNOWN = &sch.NullType{}
+	case "JSON":
+		lt.JSON = &sch.JsonType{}
+		a.ConvertedType = ct(sch.ConvertedType_JSON)
+	case "BSON":
+		lt.BSON = &sch.BsonType{}
+		a.ConvertedType = ct(sch.ConvertedType_BSON)
+	case "UUID":
+		lt.UUID = &sch.UUIDType{}
+	case "INTERVAL":
+		lt = nil
+		a.ConvertedType = ct(sch.ConvertedType_INTERVAL)
+	default:
+		return Annotation{}, fmt.Errorf("unknown annotation")
+	}
+
+	a.LogicalType = lt
+	return a, nil
+}
+
+// Set sets the logical and converted type (and the precision
+// and scale of a decimal) of se to the ones of a.
+func (a Annotation) Set(se *sch.SchemaElement) {
+	se.LogicalType = a.LogicalType
+	se.ConvertedType = a.ConvertedType
+	if a.LogicalType != nil && a.LogicalType.IsSetDECIMAL() {
+		precision, scale := a.LogicalType.DECIMAL.Precision, a.LogicalType.DECIMAL.Scale
+		se.Precision = &precision
+		se.Scale = &scale
+	}
+}
+
+// annotated is true if any of annotations has a logical or converted type
+func annotated(annotations []Annotation) bool {
+	for _, a := range annotations {
+		if a.LogicalType != nil || a.ConvertedType != nil {
+			return true
+		}
+	}
+	return false
+}
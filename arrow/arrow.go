@@ -0,0 +1,779 @@
+// Package arrow converts parquet files to Apache Arrow records and
+// writes Arrow records to parquet files.  Optional columns are nullable
+// Arrow fields, repeated columns are lists (whose elements can't be null)
+// and groups are structs.  Strings, dates, timestamps and decimals are
+// written with the matching logical types (STRING, DATE, TIMESTAMP and
+// DECIMAL) and read back as the same Arrow types.  The Arrow schema of
+// the written records is stored in the file's key_value_metadata (under
+// SchemaKey) so that it is restored when the file is read.
+package arrow
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/parsyl/parquet"
+	sch "github.com/parsyl/parquet/schema"
+)
+
+const (
+	// SchemaKey is the key_value_metadata key of the Arrow schema
+	// (a base64 encoded IPC stream) of the records in a file.
+	SchemaKey = "ARROW:schema"
+
+	// FieldIDKey is the key of the metadata of an Arrow field
+	// that holds the field_id of its parquet column.
+	FieldIDKey = "PARQUET:field_id"
+)
+
+var (
+	arrowTypes = map[reflect.Kind]arrow.DataType{
+		reflect.Int32:   arrow.PrimitiveTypes.Int32,
+		reflect.Uint32:  arrow.PrimitiveTypes.Uint32,
+		reflect.Int64:   arrow.PrimitiveTypes.Int64,
+		reflect.Uint64:  arrow.PrimitiveTypes.Uint64,
+		reflect.Float32: arrow.PrimitiveTypes.Float32,
+		reflect.Float64: arrow.PrimitiveTypes.Float64,
+		reflect.Bool:    arrow.FixedWidthTypes.Boolean,
+		reflect.String:  arrow.BinaryTypes.String,
+	}
+
+	nodes = map[arrow.Type]func(string) parquet.Node{
+		arrow.INT32:   parquet.Int32Node,
+		arrow.UINT32:  parquet.Uint32Node,
+		arrow.INT64:   parquet.Int64Node,
+		arrow.UINT64:  parquet.Uint64Node,
+		arrow.FLOAT32: parquet.Float32Node,
+		arrow.FLOAT64: parquet.Float64Node,
+		arrow.BOOL:    parquet.BoolNode,
+	}
+
+	// arrowUnits are the Arrow units of the units of a TIMESTAMP column
+	arrowUnits = map[string]arrow.TimeUnit{
+		"MILLIS": arrow.Millisecond,
+		"MICROS": arrow.Microsecond,
+		"NANOS":  arrow.Nanosecond,
+	}
+)
+
+// Schema returns the Arrow schema of a parquet file's footer (see
+// parquet.ReadMetaData).  The schema that is stored under SchemaKey
+// is used if it matches the columns of the file.
+func Schema(footer *sch.FileMetaData) (*arrow.Schema, error) {
+	s, err := parquet.SchemaOf(footer)
+	if err != nil {
+		return nil, err
+	}
+	return arrowSchema(s, footer.KeyValueMetadata), nil
+}
+
+// ParquetSchema returns the parquet schema of the records
+// with the Arrow schema s (see parquet.NewDynamicWriter).
+func ParquetSchema(s *arrow.Schema) (*parquet.Schema, error) {
+	out := make([]parquet.Node, s.NumFields())
+	for i, f := range s.Fields() {
+		n, err := parquetNode(f)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = n
+	}
+	return parquet.NewSchema(out...)
+}
+
+func arrowSchema(s *parquet.Schema, kv []*sch.KeyValue) *arrow.Schema {
+	fields := make([]arrow.Field, len(s.Nodes()))
+	for i, n := range s.Nodes() {
+		fields[i] = arrowField(n)
+	}
+
+	stored, err := decodeSchema(kv)
+	if err == nil && stored != nil && compatible(stored.Fields(), fields) {
+		return stored
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+func arrowField(n parquet.Node) arrow.Field {
+	typ := arrowType(n)
+	if n.Kind() == reflect.Invalid {
+		children := make([]arrow.Field, len(n.Children()))
+		for i, c := range n.Children() {
+			children[i] = arrowField(c)
+		}
+		typ = arrow.StructOf(children...)
+	}
+
+	f := arrow.Field{Name: n.Name(), Type: typ, Nullable: n.RepetitionType() == parquet.Optional}
+	if n.RepetitionType() == parquet.Repeated {
+		f.Type = arrow.ListOfNonNullable(typ)
+	}
+
+	if n.ID() != 0 {
+		f.Metadata = arrow.NewMetadata([]string{FieldIDKey}, []string{strconv.Itoa(int(n.ID()))})
+	}
+	return f
+}
+
+// arrowType is the Arrow type of the values of the column n, which
+// is the type of its logical type if Arrow has one.
+func arrowType(n parquet.Node) arrow.DataType {
+	lt := n.Annotation().LogicalType
+	switch {
+	case lt == nil:
+	case lt.IsSetDATE() && n.Kind() == reflect.Int32:
+		return arrow.FixedWidthTypes.Date32
+	case lt.IsSetTIMESTAMP() && n.Kind() == reflect.Int64:
+		ts := &arrow.TimestampType{Unit: arrowUnits[timeUnit(lt.TIMESTAMP.Unit)]}
+		if lt.TIMESTAMP.IsAdjustedToUTC {
+			ts.TimeZone = "UTC"
+		}
+		return ts
+	case lt.IsSetDECIMAL() && (n.Kind() == reflect.Int32 || n.Kind() == reflect.Int64):
+		return &arrow.Decimal128Type{Precision: lt.DECIMAL.Precision, Scale: lt.DECIMAL.Scale}
+	}
+	return arrowTypes[n.Kind()]
+}
+
+func timeUnit(u *sch.TimeUnit) string {
+	switch {
+	case u.IsSetMILLIS():
+		return "MILLIS"
+	case u.IsSetMICROS():
+		return "MICROS"
+	}
+	return "NANOS"
+}
+
+// leafNode returns the node of a column whose values have the Arrow
+// type typ, along with the logical type that matches typ.
+func leafNode(name string, typ arrow.DataType) (parquet.Node, error) {
+	var n parquet.Node
+	var a parquet.Annotation
+	var err error
+	switch t := typ.(type) {
+	case *arrow.StringType:
+		n = parquet.StringNode(name)
+		a, err = parquet.NewAnnotation("STRING")
+	case *arrow.Date32Type:
+		n = parquet.Int32Node(name)
+		a, err = parquet.NewAnnotation("DATE")
+	case *arrow.TimestampType:
+		var unit string
+		for u, au := range arrowUnits {
+			if au == t.Unit {
+				unit = u
+			}
+		}
+		if unit == "" {
+			return parquet.Node{}, fmt.Errorf("unsupported arrow type %s of field %s (timestamps in seconds can't be written)", typ, name)
+		}
+		n = parquet.Int64Node(name)
+		a, err = parquet.NewAnnotation("TIMESTAMP", unit, strconv.FormatBool(t.TimeZone != ""))
+	case *arrow.Decimal128Type:
+		switch {
+		case t.Precision <= 9:
+			n = parquet.Int32Node(name)
+		case t.Precision <= 18:
+			n = parquet.Int64Node(name)
+		default:
+			return parquet.Node{}, fmt.Errorf("unsupported arrow type %s of field %s (the precision of a decimal can't be more than 18)", typ, name)
+		}
+		a, err = parquet.NewAnnotation("DECIMAL", strconv.Itoa(int(t.Precision)), strconv.Itoa(int(t.Scale)))
+	default:
+		node, ok := nodes[typ.ID()]
+		if !ok {
+			return parquet.Node{}, fmt.Errorf("unsupported arrow type %s of field %s", typ, name)
+		}
+		return node(name), nil
+	}
+
+	if err != nil {
+		return parquet.Node{}, fmt.Errorf("unsupported arrow type %s of field %s, err: %s", typ, name, err)
+	}
+	return n.WithAnnotation(a), nil
+}
+
+func parquetNode(f arrow.Field) (parquet.Node, error) {
+	typ := f.Type
+	l, repeated := typ.(*arrow.ListType)
+	if repeated {
+		typ = l.Elem()
+		if _, ok := typ.(*arrow.ListType); ok {
+			return parquet.Node{}, fmt.Errorf("unsupported arrow type %s of field %s (lists of lists can't be written)", f.Type, f.Name)
+		}
+	}
+
+	var n parquet.Node
+	if st, ok := typ.(*arrow.StructType); ok {
+		children := make([]parquet.Node, st.NumFields())
+		for i, c := range st.Fields() {
+			x, err := parquetNode(c)
+			if err != nil {
+				return parquet.Node{}, err
+			}
+			children[i] = x
+		}
+		n = parquet.GroupNode(f.Name, children...)
+	} else {
+		var err error
+		if n, err = leafNode(f.Name, typ); err != nil {
+			return parquet.Node{}, err
+		}
+	}
+
+	if repeated {
+		n = n.Repeated()
+	} else if f.Nullable {
+		n = n.Optional()
+	}
+
+	if i := f.Metadata.FindKey(FieldIDKey); i >= 0 {
+		id, err := strconv.ParseInt(f.Metadata.Values()[i], 10, 32)
+		if err != nil {
+			return parquet.Node{}, fmt.Errorf("invalid %s of field %s, err: %s", FieldIDKey, f.Name, err)
+		}
+		n = n.WithID(int32(id))
+	}
+	return n, nil
+}
+
+// compatible is true if the values of the fields of a file (derived)
+// can be read into the fields of a stored schema.
+func compatible(stored, derived []arrow.Field) bool {
+	if len(stored) != len(derived) {
+		return false
+	}
+
+	for i, d := range derived {
+		s := stored[i]
+		if s.Name != d.Name || (d.Nullable && !s.Nullable) || !compatibleType(s.Type, d.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+func compatibleType(stored, derived arrow.DataType) bool {
+	switch d := derived.(type) {
+	case *arrow.ListType:
+		s, ok := stored.(*arrow.ListType)
+		return ok && compatibleType(s.Elem(), d.Elem())
+	case *arrow.StructType:
+		s, ok := stored.(*arrow.StructType)
+		return ok && compatible(s.Fields(), d.Fields())
+	case *arrow.TimestampType:
+		// the file only has whether the timestamps are in UTC, not their zone
+		s, ok := stored.(*arrow.TimestampType)
+		return ok && s.Unit == d.Unit && (s.TimeZone == "") == (d.TimeZone == "")
+	}
+	return arrow.TypeEqual(stored, derived)
+}
+
+func encodeSchema(s *arrow.Schema) (string, error) {
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(s))
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decodeSchema returns the schema that is stored under SchemaKey
+// (nil if there isn't one).
+func decodeSchema(kv []*sch.KeyValue) (*arrow.Schema, error) {
+	for _, x := range kv {
+		if x.Key != SchemaKey || x.Value == nil {
+			continue
+		}
+
+		b, err := base64.StdEncoding.DecodeString(*x.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := ipc.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Release()
+		return r.Schema(), nil
+	}
+	return nil, nil
+}
+
+// RecordReader reads a parquet file as Arrow records, one for each row
+// group.  The values of each column are decoded in bulk (see
+// parquet.Reader.ReadColumnInt32) and appended to the record's arrays
+// without creating a record (or a parquet.Row) for each row.  It
+// implements array.RecordReader.
+type RecordReader struct {
+	refs   int64
+	mem    memory.Allocator
+	schema *arrow.Schema
+	nodes  []parquet.Node
+	r      *parquet.Reader[parquet.Row]
+
+	// rowGroup is the index of the next row group to be read
+	rowGroup int
+	rec      arrow.Record
+	err      error
+}
+
+// NewRecordReader creates a RecordReader for the parquet file in r
+// whose arrays are allocated by mem.
+func NewRecordReader(r io.ReadSeeker, mem memory.Allocator) (*RecordReader, error) {
+	footer, err := parquet.ReadMetaData(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := parquet.SchemaOf(footer)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := parquet.NewCodecReader(r, s.Codec())
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordReader{
+		refs:   1,
+		mem:    mem,
+		schema: arrowSchema(s, footer.KeyValueMetadata),
+		nodes:  s.Nodes(),
+		r:      pr,
+	}, nil
+}
+
+// Retain increases the reference count of the reader
+func (r *RecordReader) Retain() {
+	atomic.AddInt64(&r.refs, 1)
+}
+
+// Release decreases the reference count of the reader and releases
+// the current record once the count is 0.
+func (r *RecordReader) Release() {
+	if atomic.AddInt64(&r.refs, -1) == 0 && r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+}
+
+// Schema is the Arrow schema of the records
+func (r *RecordReader) Schema() *arrow.Schema {
+	return r.schema
+}
+
+// Next reads the next row group.  It is false once all of the
+// row groups have been read or if there was an error (see Err).
+func (r *RecordReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+
+	if r.err != nil || r.rowGroup >= len(r.r.RowGroups()) {
+		return false
+	}
+
+	// the first row group is read by parquet.NewCodecReader
+	if r.rowGroup > 0 {
+		if r.err = r.r.ReadRowGroup(r.rowGroup); r.err != nil {
+			return false
+		}
+	}
+	r.rowGroup++
+
+	b := array.NewRecordBuilder(r.mem, r.schema)
+	defer b.Release()
+
+	for _, c := range columns(r.nodes, b) {
+		if r.err = c.read(r.r); r.err != nil {
+			return false
+		}
+	}
+
+	r.rec = b.NewRecord()
+	return true
+}
+
+// Record is the record that was read by Next.  It is released by the
+// next call to Next, so it must be retained to be used after that.
+func (r *RecordReader) Record() arrow.Record {
+	return r.rec
+}
+
+// Err is the error that stopped Next
+func (r *RecordReader) Err() error {
+	return r.err
+}
+
+// level is a node on the path of a leaf column
+type level struct {
+	rep parquet.RepetitionType
+	// def and reps are the definition and repetition
+	// levels of the node
+	def  uint8
+	reps uint8
+
+	// list is the builder of a repeated node and b is the
+	// builder of its elements (or the builder of the node)
+	list *array.ListBuilder
+	b    array.Builder
+
+	// first is true if the column is the first leaf of the node, which
+	// appends the node's lists, structs and nulls for all of its leaves.
+	first      bool
+	firstChild bool
+}
+
+// column is a leaf column along with the nodes on its path
+type column struct {
+	name   string
+	kind   reflect.Kind
+	levels []level
+}
+
+// columns returns the leaf columns of nodes, whose arrays are built by b
+func columns(nodes []parquet.Node, b *array.RecordBuilder) []column {
+	var out []column
+	var walk func(n parquet.Node, b array.Builder, pth []string, levels []level, firstChild bool)
+	walk = func(n parquet.Node, b array.Builder, pth []string, levels []level, firstChild bool) {
+		l := level{rep: n.RepetitionType(), b: b, firstChild: firstChild}
+		if len(levels) > 0 {
+			l.def, l.reps = levels[len(levels)-1].def, levels[len(levels)-1].reps
+		}
+
+		switch l.rep {
+		case parquet.Optional:
+			l.def++
+		case parquet.Repeated:
+			l.def++
+			l.reps++
+			l.list = b.(*array.ListBuilder)
+			l.b = l.list.ValueBuilder()
+		}
+
+		pth = append(pth[:len(pth):len(pth)], n.Name())
+		levels = append(levels[:len(levels):len(levels)], l)
+		if n.Kind() != reflect.Invalid {
+			first := true
+			for i := len(levels) - 1; i >= 0; i-- {
+				levels[i].first = first
+				first = first && levels[i].firstChild
+			}
+			out = append(out, column{name: strings.Join(pth, "."), kind: n.Kind(), levels: levels})
+			return
+		}
+
+		sb := l.b.(*array.StructBuilder)
+		for i, c := range n.Children() {
+			walk(c, sb.FieldBuilder(i), pth, levels, i == 0)
+		}
+	}
+
+	for i, n := range nodes {
+		walk(n, b.Field(i), nil, nil, true)
+	}
+	return out
+}
+
+func (c column) read(r *parquet.Reader[parquet.Row]) error {
+	switch c.kind {
+	case reflect.Int32:
+		return appendColumn(c, r.ReadColumnInt32)
+	case reflect.Uint32:
+		return appendColumn(c, r.ReadColumnUint32)
+	case reflect.Int64:
+		return appendColumn(c, r.ReadColumnInt64)
+	case reflect.Uint64:
+		return appendColumn(c, r.ReadColumnUint64)
+	case reflect.Float32:
+		return appendColumn(c, r.ReadColumnFloat32)
+	case reflect.Float64:
+		return appendColumn(c, r.ReadColumnFloat64)
+	case reflect.Bool:
+		return appendColumn(c, r.ReadColumnBool)
+	case reflect.String:
+		return appendColumn(c, r.ReadColumnString)
+	}
+	return fmt.Errorf("unable to read column %s, err: unsupported type %s", c.name, c.kind)
+}
+
+// valueBuilder is the builder of the values of a leaf column
+type valueBuilder[V parquet.Value] interface {
+	Append(V)
+	AppendValues([]V, []bool)
+}
+
+// builderOf returns the builder of the values of type V of a leaf
+// column, which converts them if the column has a logical type whose
+// Arrow type isn't V.
+func builderOf[V parquet.Value](b array.Builder) (valueBuilder[V], bool) {
+	var vb any = b
+	switch b := b.(type) {
+	case *array.Date32Builder:
+		vb = converter[int32, arrow.Date32]{b: b, f: func(v int32) arrow.Date32 { return arrow.Date32(v) }}
+	case *array.TimestampBuilder:
+		vb = converter[int64, arrow.Timestamp]{b: b, f: func(v int64) arrow.Timestamp { return arrow.Timestamp(v) }}
+	case *array.Decimal128Builder:
+		var v V
+		if _, ok := any(v).(int32); ok {
+			vb = converter[int32, decimal128.Num]{b: b, f: func(v int32) decimal128.Num { return decimal128.FromI64(int64(v)) }}
+		} else {
+			vb = converter[int64, decimal128.Num]{b: b, f: decimal128.FromI64}
+		}
+	}
+
+	out, ok := vb.(valueBuilder[V])
+	return out, ok
+}
+
+// converter appends values of type V to a builder of values of type A
+type converter[V any, A any] struct {
+	b interface {
+		Append(A)
+		AppendValues([]A, []bool)
+	}
+	f func(V) A
+}
+
+func (c converter[V, A]) Append(v V) {
+	c.b.Append(c.f(v))
+}
+
+func (c converter[V, A]) AppendValues(vals []V, valid []bool) {
+	out := make([]A, len(vals))
+	for i, v := range vals {
+		out[i] = c.f(v)
+	}
+	c.b.AppendValues(out, valid)
+}
+
+// appendColumn appends the values of a column to its builders
+func appendColumn[V parquet.Value](c column, read func(string) ([]V, []uint8, []uint8, error)) error {
+	vals, defs, reps, err := read(c.name)
+	if err != nil {
+		return err
+	}
+
+	b, ok := builderOf[V](c.levels[len(c.levels)-1].b)
+	if !ok {
+		return fmt.Errorf("unable to read column %s, err: it can't be appended to a %T", c.name, c.levels[len(c.levels)-1].b)
+	}
+
+	if defs == nil {
+		if len(c.levels) == 1 {
+			b.AppendValues(vals, nil)
+			return nil
+		}
+		// a required column of required structs has no levels, but
+		// the structs above it still need an entry for every value
+		defs = make([]uint8, len(vals))
+	}
+
+	var v int
+	for i, def := range defs {
+		var rep uint8
+		if reps != nil {
+			rep = reps[i]
+		}
+
+		if c.add(def, rep) {
+			b.Append(vals[v])
+			v++
+		}
+	}
+	return nil
+}
+
+// add appends the lists, structs and nulls of the column's nodes that
+// start at a value whose levels are def and rep.  It is true if the
+// value is defined, in which case it is appended by the caller.
+func (c column) add(def, rep uint8) bool {
+	for i, l := range c.levels {
+		switch l.rep {
+		case parquet.Repeated:
+			if rep > l.reps {
+				// another value of the same element
+				continue
+			}
+
+			if rep < l.reps {
+				if l.first {
+					l.list.Append(true)
+				}
+
+				if def < l.def {
+					// an empty list
+					return false
+				}
+			}
+		case parquet.Optional:
+			if rep > l.reps {
+				continue
+			}
+
+			if def < l.def {
+				c.null(i)
+				return false
+			}
+		default:
+			if rep > l.reps {
+				continue
+			}
+		}
+
+		if i == len(c.levels)-1 {
+			return true
+		}
+
+		if l.first {
+			l.b.(*array.StructBuilder).Append(true)
+		}
+	}
+	return false
+}
+
+// null appends a null to the builders of the i'th node and of the
+// nodes below it (up to the first list, whose elements aren't there).
+// The children of a struct aren't set to null by its builder since
+// each column appends its own values.
+func (c column) null(i int) {
+	for _, l := range c.levels[i:] {
+		if l.first {
+			switch {
+			case l.rep == parquet.Repeated:
+				l.list.AppendNull()
+			case i < len(c.levels)-1:
+				l.b.(*array.StructBuilder).AppendValues([]bool{false})
+			default:
+				l.b.AppendNull()
+			}
+		}
+
+		if l.rep == parquet.Repeated {
+			return
+		}
+		i++
+	}
+}
+
+// Writer writes Arrow records to a parquet file.  Each record
+// is written as a row group.
+type Writer struct {
+	w      *parquet.DynamicWriter
+	schema *arrow.Schema
+}
+
+// NewWriter creates a Writer for records with the Arrow schema s.  It
+// takes the same options as parquet.NewWriter and adds s to the file's
+// key_value_metadata under SchemaKey.
+func NewWriter(w io.Writer, s *arrow.Schema, opts ...parquet.WriterOption) (*Writer, error) {
+	ps, err := ParquetSchema(s)
+	if err != nil {
+		return nil, err
+	}
+
+	kv, err := encodeSchema(s)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the arrow schema, err: %s", err)
+	}
+
+	opts = append(opts[:len(opts):len(opts)], parquet.WithKeyValueMetadata(map[string]string{SchemaKey: kv}))
+	pw, err := parquet.NewDynamicWriter(w, ps, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{w: pw, schema: s}, nil
+}
+
+// Write writes the rows of rec as a row group.  A row whose value can't
+// be written (a null element of a list, for example) is rejected with a
+// *parquet.RecordError and the rows before it are written.
+func (w *Writer) Write(rec arrow.Record) error {
+	if !rec.Schema().Equal(w.schema) {
+		return fmt.Errorf("the record's schema (%s) isn't the writer's schema (%s)", rec.Schema(), w.schema)
+	}
+
+	cols := rec.Columns()
+	row := make([]any, len(cols))
+	for i := 0; i < int(rec.NumRows()); i++ {
+		for j, c := range cols {
+			row[j] = value(c, i)
+		}
+
+		if err := w.w.Add(row); err != nil {
+			if werr := w.w.Write(); werr != nil {
+				return werr
+			}
+			return err
+		}
+	}
+	return w.w.Write()
+}
+
+// Close writes the footer of the file
+func (w *Writer) Close() error {
+	return w.w.Close()
+}
+
+// value returns the i'th value of a (nil if it is null) as
+// one of the values of a row of a parquet.DynamicWriter.
+func value(a arrow.Array, i int) any {
+	if a.IsNull(i) {
+		return nil
+	}
+
+	switch a := a.(type) {
+	case *array.Int32:
+		return a.Value(i)
+	case *array.Uint32:
+		return a.Value(i)
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint64:
+		return a.Value(i)
+	case *array.Float32:
+		return a.Value(i)
+	case *array.Float64:
+		return a.Value(i)
+	case *array.Boolean:
+		return a.Value(i)
+	case *array.String:
+		return a.Value(i)
+	case *array.Date32:
+		return int32(a.Value(i))
+	case *array.Timestamp:
+		return int64(a.Value(i))
+	case *array.Decimal128:
+		return int64(a.Value(i).LowBits())
+	case *array.List:
+		start, end := a.ValueOffsets(i)
+		out := make([]any, end-start)
+		for j := range out {
+			out[j] = value(a.ListValues(), int(start)+j)
+		}
+		return out
+	case *array.Struct:
+		out := make([]any, a.NumField())
+		for j := range out {
+			out[j] = value(a.Field(j), i)
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package arrow_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/parsyl/parquet"
+	parrow "github.com/parsyl/parquet/arrow"
+	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/logical"
+	sch "github.com/parsyl/parquet/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var schema = arrow.NewSchema([]arrow.Field{
+	{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "age", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+	{Name: "visits", Type: arrow.PrimitiveTypes.Uint32},
+	{Name: "bytes", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+	{Name: "score", Type: arrow.PrimitiveTypes.Float32},
+	{Name: "balance", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	{Name: "active", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+	{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+	{Name: "address", Nullable: true, Type: arrow.StructOf(
+		arrow.Field{Name: "city", Type: arrow.BinaryTypes.String},
+		arrow.Field{Name: "zip", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+	)},
+	{Name: "friends", Type: arrow.ListOfNonNullable(arrow.StructOf(
+		arrow.Field{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		arrow.Field{Name: "hobbies", Type: arrow.ListOfNonNullable(arrow.StructOf(
+			arrow.Field{Name: "name", Type: arrow.BinaryTypes.String},
+			arrow.Field{Name: "difficulty", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		))},
+	))},
+}, nil)
+
+var records = []string{
+	`[
+		{"id": 1, "age": 30, "visits": 3, "bytes": 100, "score": 1.5, "balance": 10.25, "active": true, "name": "a", "tags": ["x", "y"],
+		 "address": {"city": "Boise", "zip": 83702}, "friends": [{"id": 2, "hobbies": [{"name": "golf", "difficulty": 3}, {"name": "chess"}]}, {"id": 3, "hobbies": []}]},
+		{"id": 2, "age": null, "visits": 0, "bytes": null, "score": 0, "balance": null, "active": null, "name": null, "tags": [],
+		 "address": null, "friends": []},
+		{"id": 3, "age": 5, "visits": 1, "bytes": 1099511627776, "score": -1, "balance": -3, "active": false, "name": "", "tags": ["z"],
+		 "address": {"city": "Reno", "zip": null}, "friends": [{"id": 1, "hobbies": [{"name": "golf", "difficulty": null}]}]}
+	]`,
+	`[
+		{"id": 4, "age": 41, "visits": 2, "bytes": 7, "score": 2, "balance": 1, "active": true, "name": "d", "tags": ["a", "b", "c"],
+		 "address": {"city": "Elko", "zip": 89801}, "friends": [{"id": 5, "hobbies": []}, {"id": 6, "hobbies": [{"name": "ski", "difficulty": 9}]}]}
+	]`,
+}
+
+func TestRoundTrip(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	var expected []arrow.Record
+	for _, js := range records {
+		rec, _, err := array.RecordFromJSON(mem, schema, strings.NewReader(js))
+		require.NoError(t, err)
+		defer rec.Release()
+		expected = append(expected, rec)
+	}
+
+	var buf bytes.Buffer
+	w, err := parrow.NewWriter(&buf, schema, parquet.WithKeyValueMetadata(map[string]string{"a": "b"}))
+	require.NoError(t, err)
+	for _, rec := range expected {
+		require.NoError(t, w.Write(rec))
+	}
+	require.NoError(t, w.Close())
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Len(t, footer.RowGroups, 2)
+	var keys []string
+	for _, kv := range footer.KeyValueMetadata {
+		keys = append(keys, kv.Key)
+	}
+	assert.Equal(t, []string{parrow.SchemaKey, "a"}, keys)
+
+	r, err := parrow.NewRecordReader(bytes.NewReader(buf.Bytes()), mem)
+	require.NoError(t, err)
+	defer r.Release()
+
+	assert.True(t, r.Schema().Equal(schema))
+
+	var i int
+	for r.Next() {
+		require.Less(t, i, len(expected))
+		assert.True(t, array.RecordEqual(expected[i], r.Record()), "record %d:\n%v\n%v", i, expected[i], r.Record())
+		i++
+	}
+	assert.NoError(t, r.Err())
+	assert.Equal(t, len(expected), i)
+}
+
+func TestSchema(t *testing.T) {
+	str, err := parquet.NewAnnotation("STRING")
+	require.NoError(t, err)
+
+	s, err := parquet.NewSchema(
+		parquet.Int64Node("id").WithID(1),
+		parquet.Uint32Node("n").Optional(),
+		parquet.StringNode("tags").Repeated().WithAnnotation(str),
+		parquet.GroupNode("link",
+			parquet.BoolNode("ok"),
+			parquet.Float64Node("weight").Optional(),
+		).Optional(),
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := parquet.NewDynamicWriter(&buf, s)
+	require.NoError(t, err)
+	require.NoError(t, w.Add([]any{1, 2, []string{"a", "b"}, map[string]any{"ok": true}}))
+	require.NoError(t, w.Add([]any{2, nil, nil, nil}))
+	require.NoError(t, w.Write())
+	require.NoError(t, w.Close())
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	as, err := parrow.Schema(footer)
+	require.NoError(t, err)
+
+	expected := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64, Metadata: arrow.NewMetadata([]string{parrow.FieldIDKey}, []string{"1"})},
+		{Name: "n", Type: arrow.PrimitiveTypes.Uint32, Nullable: true},
+		{Name: "tags", Type: arrow.ListOfNonNullable(arrow.BinaryTypes.String)},
+		{Name: "link", Nullable: true, Type: arrow.StructOf(
+			arrow.Field{Name: "ok", Type: arrow.FixedWidthTypes.Boolean},
+			arrow.Field{Name: "weight", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		)},
+	}, nil)
+	assert.True(t, as.Equal(expected), "%s", as)
+
+	ps, err := parrow.ParquetSchema(as)
+	require.NoError(t, err)
+	assert.Equal(t, s.Nodes(), ps.Nodes())
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	r, err := parrow.NewRecordReader(bytes.NewReader(buf.Bytes()), mem)
+	require.NoError(t, err)
+	defer r.Release()
+
+	require.True(t, r.Next())
+	js, err := r.Record().MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"id": 1, "n": 2, "tags": ["a", "b"], "link": {"ok": true, "weight": null}},
+		{"id": 2, "n": null, "tags": [], "link": null}
+	]`, string(js))
+	assert.False(t, r.Next())
+	assert.NoError(t, r.Err())
+}
+
+func TestUnsupported(t *testing.T) {
+	testCases := []struct {
+		name   string
+		schema *arrow.Schema
+		err    string
+	}{
+		{
+			name:   "binary",
+			schema: arrow.NewSchema([]arrow.Field{{Name: "b", Type: arrow.BinaryTypes.Binary}}, nil),
+			err:    "unsupported arrow type binary of field b",
+		},
+		{
+			name: "nested",
+			schema: arrow.NewSchema([]arrow.Field{{Name: "g", Type: arrow.StructOf(
+				arrow.Field{Name: "t", Type: arrow.FixedWidthTypes.Time32ms},
+			)}}, nil),
+			err: "unsupported arrow type time32[ms] of field t",
+		},
+		{
+			name:   "timestamp in seconds",
+			schema: arrow.NewSchema([]arrow.Field{{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_s}}, nil),
+			err:    "unsupported arrow type timestamp[s, tz=UTC] of field ts (timestamps in seconds can't be written)",
+		},
+		{
+			name:   "wide decimal",
+			schema: arrow.NewSchema([]arrow.Field{{Name: "d", Type: &arrow.Decimal128Type{Precision: 20, Scale: 2}}}, nil),
+			err:    "unsupported arrow type decimal(20, 2) of field d (the precision of a decimal can't be more than 18)",
+		},
+		{
+			name:   "list of lists",
+			schema: arrow.NewSchema([]arrow.Field{{Name: "l", Type: arrow.ListOf(arrow.ListOf(arrow.PrimitiveTypes.Int32))}}, nil),
+			err:    "unsupported arrow type list<item: list<item: int32, nullable>, nullable> of field l (lists of lists can't be written)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parrow.NewWriter(&bytes.Buffer{}, tc.schema)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestNullElement(t *testing.T) {
+	s := arrow.NewSchema([]arrow.Field{{Name: "l", Type: arrow.ListOf(arrow.PrimitiveTypes.Int32)}}, nil)
+	rec, _, err := array.RecordFromJSON(memory.DefaultAllocator, s, strings.NewReader(`[{"l": [1]}, {"l": [1, null]}]`))
+	require.NoError(t, err)
+	defer rec.Release()
+
+	w, err := parrow.NewWriter(&bytes.Buffer{}, s)
+	require.NoError(t, err)
+
+	err = w.Write(rec)
+	var rerr *parquet.RecordError
+	assert.ErrorAs(t, err, &rerr)
+	assert.Equal(t, "l", rerr.Field)
+}
+
+func TestRequiredStruct(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	s := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "a", Type: arrow.StructOf(
+			arrow.Field{Name: "x", Type: arrow.PrimitiveTypes.Int32},
+			arrow.Field{Name: "b", Type: arrow.StructOf(
+				arrow.Field{Name: "y", Type: arrow.BinaryTypes.String},
+			)},
+		)},
+	}, nil)
+
+	expected, _, err := array.RecordFromJSON(mem, s, strings.NewReader(`[
+		{"id": 1, "a": {"x": 10, "b": {"y": "p"}}},
+		{"id": 2, "a": {"x": 20, "b": {"y": "q"}}},
+		{"id": 3, "a": {"x": 30, "b": {"y": "r"}}}
+	]`))
+	require.NoError(t, err)
+	defer expected.Release()
+
+	var buf bytes.Buffer
+	w, err := parrow.NewWriter(&buf, s)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(expected))
+	require.NoError(t, w.Close())
+
+	r, err := parrow.NewRecordReader(bytes.NewReader(buf.Bytes()), mem)
+	require.NoError(t, err)
+	defer r.Release()
+
+	require.True(t, r.Next())
+	assert.Equal(t, int64(3), r.Record().NumRows())
+	assert.True(t, array.RecordEqual(expected, r.Record()), "%v\n%v", expected, r.Record())
+	assert.False(t, r.Next())
+	assert.NoError(t, r.Err())
+}
+
+func TestLogicalTypes(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	s := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "at", Type: arrow.FixedWidthTypes.Timestamp_ms},
+		{Name: "local", Type: &arrow.TimestampType{Unit: arrow.Microsecond}, Nullable: true},
+		{Name: "price", Type: &arrow.Decimal128Type{Precision: 9, Scale: 2}},
+		{Name: "total", Type: &arrow.Decimal128Type{Precision: 18, Scale: 4}, Nullable: true},
+	}, nil)
+
+	expected, _, err := array.RecordFromJSON(mem, s, strings.NewReader(`[
+		{"name": "a", "day": 19000, "at": 1700000000000, "local": 1700000000000000, "price": "10.50", "total": "-12345678901234.5678"},
+		{"name": "b", "day": null, "at": 0, "local": null, "price": "-0.01", "total": null}
+	]`))
+	require.NoError(t, err)
+	defer expected.Release()
+
+	var buf bytes.Buffer
+	w, err := parrow.NewWriter(&buf, s)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(expected))
+	require.NoError(t, w.Close())
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	lts := make([]*sch.LogicalType, len(footer.Schema)-1)
+	for i, se := range footer.Schema[1:] {
+		lts[i] = se.LogicalType
+	}
+	assert.Equal(t, []*sch.LogicalType{
+		{STRING: &sch.StringType{}},
+		{DATE: &sch.DateType{}},
+		{TIMESTAMP: &sch.TimestampType{IsAdjustedToUTC: true, Unit: &sch.TimeUnit{MILLIS: &sch.MilliSeconds{}}}},
+		{TIMESTAMP: &sch.TimestampType{Unit: &sch.TimeUnit{MICROS: &sch.MicroSeconds{}}}},
+		{DECIMAL: &sch.DecimalType{Precision: 9, Scale: 2}},
+		{DECIMAL: &sch.DecimalType{Precision: 18, Scale: 4}},
+	}, lts)
+	assert.Equal(t, sch.ConvertedType_UTF8, footer.Schema[1].GetConvertedType())
+	assert.Equal(t, sch.Type_INT32, footer.Schema[5].GetType())
+	assert.Equal(t, sch.Type_INT64, footer.Schema[6].GetType())
+
+	// the arrow schema is derived from the logical types without the stored one
+	footer.KeyValueMetadata = nil
+	as, err := parrow.Schema(footer)
+	require.NoError(t, err)
+	assert.True(t, as.Equal(s), "%s", as)
+
+	r, err := parrow.NewRecordReader(bytes.NewReader(buf.Bytes()), mem)
+	require.NoError(t, err)
+	defer r.Release()
+
+	require.True(t, r.Next())
+	assert.True(t, array.RecordEqual(expected, r.Record()), "%v\n%v", expected, r.Record())
+	assert.False(t, r.Next())
+	assert.NoError(t, r.Err())
+}
+
+func TestReadGenerated(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	var buf bytes.Buffer
+	w, err := logical.NewParquetWriter(&buf)
+	require.NoError(t, err)
+	due := int32(19000)
+	require.NoError(t, w.Add(logical.Invoice{Id: 1, Total: 1050, Due: &due, Customer: "a", Lines: &logical.Lines{List: []logical.List{{Element: "x"}}}}))
+	require.NoError(t, w.Add(logical.Invoice{Id: 2, Total: -25, Customer: "b"}))
+	require.NoError(t, w.Write())
+	require.NoError(t, w.Close())
+
+	r, err := parrow.NewRecordReader(bytes.NewReader(buf.Bytes()), mem)
+	require.NoError(t, err)
+	defer r.Release()
+
+	assert.True(t, arrow.TypeEqual(&arrow.Decimal128Type{Precision: 9, Scale: 2}, r.Schema().Field(1).Type))
+	require.True(t, r.Next())
+	js, err := r.Record().MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"id": 1, "total": "10.5", "due": "2022-01-08", "customer": "a", "lines": {"list": [{"element": "x"}]}},
+		{"id": 2, "total": "-0.25", "due": null, "customer": "b", "lines": null}
+	]`, string(js))
+	assert.False(t, r.Next())
+	assert.NoError(t, r.Err())
+}
@@ -0,0 +1,35 @@
+module github.com/parsyl/parquet/arrow
+
+go 1.23.0
+
+require (
+	github.com/apache/arrow-go/v18 v18.4.0
+	github.com/parsyl/parquet v0.0.0-20261019093723-226b06f24f8c
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/apache/thrift v0.22.0 // indirect
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/goccy/go-json v0.10.5 // indirect
+	github.com/golang/snappy v1.0.0 // indirect
+	github.com/google/flatbuffers v25.2.10+incompatible // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.11 // indirect
+	github.com/pierrec/lz4/v4 v4.1.22 // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/zeebo/xxh3 v1.0.2 // indirect
+	golang.org/x/exp v0.0.0-20250408133849-7e4ce0ab07d0 // indirect
+	golang.org/x/mod v0.25.0 // indirect
+	golang.org/x/sync v0.15.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/tools v0.34.0 // indirect
+	golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// Builds in this repository use the parquet module next to this one.  Go
+// ignores replace directives in dependencies, so the modules that require
+// this one get the version of github.com/parsyl/parquet that is required above.
+replace github.com/parsyl/parquet => ../
@@ -0,0 +1,541 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// Node is a column or a group of columns of a Schema.  A Node
+// is required unless Optional or Repeated is called.
+type Node struct {
+	name       string
+	kind       reflect.Kind
+	rep        RepetitionType
+	id         int32
+	annotation Annotation
+	children   []Node
+}
+
+// Int32Node is an INT32 column
+func Int32Node(name string) Node { return Node{name: name, kind: reflect.Int32} }
+
+// Uint32Node is an INT32 column with the UINT_32 converted type
+func Uint32Node(name string) Node { return Node{name: name, kind: reflect.Uint32} }
+
+// Int64Node is an INT64 column
+func Int64Node(name string) Node { return Node{name: name, kind: reflect.Int64} }
+
+// Uint64Node is an INT64 column with the UINT_64 converted type
+func Uint64Node(name string) Node { return Node{name: name, kind: reflect.Uint64} }
+
+// Float32Node is a FLOAT column
+func Float32Node(name string) Node { return Node{name: name, kind: reflect.Float32} }
+
+// Float64Node is a DOUBLE column
+func Float64Node(name string) Node { return Node{name: name, kind: reflect.Float64} }
+
+// BoolNode is a BOOLEAN column
+func BoolNode(name string) Node { return Node{name: name, kind: reflect.Bool} }
+
+// StringNode is a BYTE_ARRAY column
+func StringNode(name string) Node { return Node{name: name, kind: reflect.String} }
+
+// GroupNode is a group of columns
+func GroupNode(name string, children ...Node) Node {
+	return Node{name: name, children: children}
+}
+
+// Optional returns a copy of n that can be null
+func (n Node) Optional() Node {
+	n.rep = Optional
+	return n
+}
+
+// Repeated returns a copy of n that holds a list
+func (n Node) Repeated() Node {
+	n.rep = Repeated
+	return n
+}
+
+// WithID returns a copy of n with a field_id
+func (n Node) WithID(id int32) Node {
+	n.id = id
+	return n
+}
+
+// WithAnnotation returns a copy of n with the logical and converted
+// type of a (see ParseAnnotation), for example a DECIMAL column or a
+// LIST group.
+func (n Node) WithAnnotation(a Annotation) Node {
+	n.annotation = a
+	return n
+}
+
+// Name is the name of the column or group
+func (n Node) Name() string {
+	return n.name
+}
+
+// Kind is the Go type of the column's values (reflect.Invalid for a group)
+func (n Node) Kind() reflect.Kind {
+	return n.kind
+}
+
+// RepetitionType is Required, Optional or Repeated
+func (n Node) RepetitionType() RepetitionType {
+	return n.rep
+}
+
+// ID is the field_id of the node (0 if it doesn't have one)
+func (n Node) ID() int32 {
+	return n.id
+}
+
+// Annotation is the logical and converted type of the node
+func (n Node) Annotation() Annotation {
+	return n.annotation
+}
+
+// Children are the nodes of a group
+func (n Node) Children() []Node {
+	return n.children
+}
+
+// Schema describes the columns of the rows that are written by a
+// DynamicWriter (and read by a DynamicReader).
+type Schema struct {
+	nodes  []Node
+	leaves []schemaLeaf
+}
+
+// schemaLeaf is a column of a Schema
+type schemaLeaf struct {
+	path  []string
+	types []RepetitionType
+	kind  reflect.Kind
+	id    int32
+
+	// annotations are the annotation of each level of path
+	annotations []Annotation
+}
+
+// NewSchema creates a schema out of the top level nodes of a row.
+func NewSchema(nodes ...Node) (*Schema, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("schema has no columns")
+	}
+
+	if err := checkNodes(nodes, ""); err != nil {
+		return nil, err
+	}
+	return newSchema(nodes), nil
+}
+
+func checkNodes(nodes []Node, prefix string) error {
+	names := map[string]bool{}
+	for _, n := range nodes {
+		pth := prefix + n.name
+		if n.name == "" || strings.Contains(n.name, ".") {
+			return fmt.Errorf("invalid name %q in %q", n.name, prefix)
+		}
+
+		if names[n.name] {
+			return fmt.Errorf("%s is defined more than once", pth)
+		}
+		names[n.name] = true
+
+		if n.kind != reflect.Invalid {
+			continue
+		}
+
+		if len(n.children) == 0 {
+			return fmt.Errorf("group %s has no columns", pth)
+		}
+
+		if err := checkNodes(n.children, pth+"."); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newSchema(nodes []Node) *Schema {
+	s := &Schema{nodes: nodes}
+	var walk func(nodes []Node, pth []string, types []RepetitionType, annotations []Annotation)
+	walk = func(nodes []Node, pth []string, types []RepetitionType, annotations []Annotation) {
+		for _, n := range nodes {
+			p := append(pth[:len(pth):len(pth)], n.name)
+			ts := append(types[:len(types):len(types)], n.rep)
+			as := append(annotations[:len(annotations):len(annotations)], n.annotation)
+			if n.kind == reflect.Invalid {
+				walk(n.children, p, ts, as)
+				continue
+			}
+			s.leaves = append(s.leaves, schemaLeaf{path: p, types: ts, kind: n.kind, id: n.id, annotations: as})
+		}
+	}
+	walk(nodes, nil, nil, nil)
+	return s
+}
+
+// Nodes are the top level nodes of the schema
+func (s *Schema) Nodes() []Node {
+	return s.nodes
+}
+
+// Fields are the columns of the schema (see New).  They are the
+// same as the Fields of the code that parquetgen generates for
+// a struct with the same columns.
+func (s *Schema) Fields() []Field {
+	out := make([]Field, len(s.leaves))
+	for i, c := range s.columns(sch.CompressionCodec_UNCOMPRESSED) {
+		out[i] = c.Schema()
+	}
+	return out
+}
+
+// columns creates the columns of the schema
+func (s *Schema) columns(c sch.CompressionCodec) []Column[Row] {
+	out := make([]Column[Row], len(s.leaves))
+	for i, l := range s.leaves {
+		out[i] = &dynamicColumn{
+			leafColumn: newLeafColumn(l.path, l.types, l.kind, l.id, l.annotations, c),
+			names:      l.path,
+		}
+	}
+	return out
+}
+
+// Codec returns the Codec of the schema's rows, which can be used to
+// read a file with NewCodecReader (a Reader[Row] reads the same rows as
+// a DynamicReader and gives access to the values of each column with
+// ReadColumnInt32 and friends).  Rows should be written by a DynamicWriter,
+// which converts them to the types of the columns.
+func (s *Schema) Codec() Codec[Row] {
+	return schemaCodec{s}
+}
+
+type schemaCodec struct {
+	s *Schema
+}
+
+func (c schemaCodec) Fields(cc sch.CompressionCodec) []Column[Row] {
+	return c.s.columns(cc)
+}
+
+// row turns v (a Row, map[string]any or []any) into a Row whose
+// groups are map[string]any, whose lists are []any and whose values
+// have the types of their columns.
+func (s *Schema) row(v any) (Row, error) {
+	m, err := groupValue(s.nodes, v, "")
+	return Row(m), err
+}
+
+func groupValue(nodes []Node, v any, prefix string) (map[string]any, error) {
+	out := make(map[string]any, len(nodes))
+	switch x := v.(type) {
+	case Row:
+		return groupValue(nodes, map[string]any(x), prefix)
+	case map[string]any:
+		for k := range x {
+			if !hasNode(nodes, k) {
+				return nil, &RecordError{Field: prefix + k, Err: fmt.Errorf("not in the schema")}
+			}
+		}
+
+		for _, n := range nodes {
+			val, err := n.value(x[n.name], prefix)
+			if err != nil {
+				return nil, err
+			}
+			out[n.name] = val
+		}
+	case []any:
+		if len(x) != len(nodes) {
+			return nil, &RecordError{Field: strings.TrimSuffix(prefix, "."), Err: fmt.Errorf("%d values, expected %d", len(x), len(nodes))}
+		}
+
+		for i, n := range nodes {
+			val, err := n.value(x[i], prefix)
+			if err != nil {
+				return nil, err
+			}
+			out[n.name] = val
+		}
+	default:
+		return nil, &RecordError{Field: strings.TrimSuffix(prefix, "."), Err: fmt.Errorf("%T is not a map[string]any or []any", v)}
+	}
+	return out, nil
+}
+
+func hasNode(nodes []Node, name string) bool {
+	for _, n := range nodes {
+		if n.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the value of n in a Row.  Pointers are
+// dereferenced and nil pointers are null.
+func (n Node) value(v any, prefix string) (any, error) {
+	pth := prefix + n.name
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		if n.rep == Required {
+			return nil, &RecordError{Field: pth, Err: fmt.Errorf("required value is null")}
+		}
+		return nil, nil
+	}
+
+	if n.rep != Repeated {
+		return n.element(rv, pth)
+	}
+
+	if rv.Kind() != reflect.Slice {
+		return nil, &RecordError{Field: pth, Err: fmt.Errorf("%s is not a slice", rv.Type())}
+	}
+
+	out := make([]any, rv.Len())
+	for i := range out {
+		e := rv.Index(i)
+		for e.Kind() == reflect.Interface || (e.Kind() == reflect.Ptr && !e.IsNil()) {
+			e = e.Elem()
+		}
+
+		if !e.IsValid() || (e.Kind() == reflect.Ptr && e.IsNil()) {
+			return nil, &RecordError{Field: pth, Err: fmt.Errorf("element %d is null", i)}
+		}
+
+		x, err := n.element(e, pth)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = x
+	}
+	return out, nil
+}
+
+// element returns a single value (or group) of n
+func (n Node) element(rv reflect.Value, pth string) (any, error) {
+	if n.kind == reflect.Invalid {
+		return groupValue(n.children, rv.Interface(), pth+".")
+	}
+
+	v, err := convert(rv, n.kind)
+	if err != nil {
+		return nil, &RecordError{Field: pth, Err: err}
+	}
+	return v, nil
+}
+
+// convert converts v to the Go type of kind.  Numbers can be converted
+// to any numeric column as long as the value doesn't change (other than
+// the precision of a float).
+func convert(v reflect.Value, kind reflect.Kind) (any, error) {
+	t := kinds[kind].goType
+	var ok bool
+	switch kind {
+	case reflect.Bool:
+		ok = v.Kind() == reflect.Bool
+	case reflect.String:
+		ok = v.Kind() == reflect.String
+	default:
+		ok = v.CanInt() || v.CanUint() || v.CanFloat()
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%s can't be written to a %s column", v.Type(), kind)
+	}
+
+	c := v.Convert(t)
+	changed := !c.CanFloat() && !c.Convert(v.Type()).Equal(v)
+	changed = changed || (v.CanInt() && c.CanUint() && v.Int() < 0) || (v.CanUint() && c.CanInt() && c.Int() < 0)
+	if changed {
+		return nil, fmt.Errorf("%v overflows a %s column", v, kind)
+	}
+	return c.Interface(), nil
+}
+
+// dynamicColumn is a Column of a Schema whose values are read from
+// and written to Rows.
+type dynamicColumn struct {
+	*leafColumn
+	names []string
+}
+
+// walk calls f with each value (and its levels) of the column in v.
+// The value is nil if it is null.
+func (c *dynamicColumn) walk(v any, depth int, def, rep uint8, f func(v any, def, rep uint8)) {
+	if depth == len(c.names) {
+		f(v, def, rep)
+		return
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		m = v.(Row)
+	}
+
+	fv := m[c.names[depth]]
+	switch c.types[depth] {
+	case Optional:
+		if fv == nil {
+			f(nil, def, rep)
+			return
+		}
+		c.walk(fv, depth+1, def+1, rep, f)
+	case Repeated:
+		s, _ := fv.([]any)
+		if len(s) == 0 {
+			f(nil, def, rep)
+			return
+		}
+		for i, x := range s {
+			r := rep
+			if i > 0 {
+				r = c.reps[depth]
+			}
+			c.walk(x, depth+1, def+1, r, f)
+		}
+	default:
+		c.walk(fv, depth+1, def, rep, f)
+	}
+}
+
+func (c *dynamicColumn) Add(row Row) {
+	c.walk(row, 0, 0, 0, func(v any, def, rep uint8) {
+		var rv reflect.Value
+		if v != nil {
+			rv = reflect.ValueOf(v)
+		}
+		c.add(rv, def, rep)
+	})
+}
+
+func (c *dynamicColumn) Validate(row Row, checks Checks) error {
+	var err error
+	c.walk(row, 0, 0, 0, func(v any, _, _ uint8) {
+		if err == nil && v != nil {
+			err = c.vals.check(checks, c.Name(), reflect.ValueOf(v))
+		}
+	})
+	return err
+}
+
+func (c *dynamicColumn) Scan(row *Row) {
+	if *row == nil {
+		*row = Row{}
+	}
+
+	c.scan(func(def uint8, indices []int, i int) {
+		var v any
+		if i >= 0 {
+			v = c.vals.get(i)
+		}
+		c.set(*row, def, indices, v)
+	})
+}
+
+// set creates the groups and the elements of the repeated fields of row
+// that are defined by def and then sets the leaf to v if it is defined.
+// Fields that aren't defined are set to nil.
+func (c *dynamicColumn) set(row Row, def uint8, indices []int, v any) {
+	m := map[string]any(row)
+	var d uint8
+	var r int
+	for depth, t := range c.types {
+		name := c.names[depth]
+		leaf := depth == len(c.types)-1
+
+		if t != Required {
+			if def <= d {
+				if _, ok := m[name]; !ok {
+					m[name] = nil
+				}
+				return
+			}
+			d++
+		}
+
+		if t == Repeated {
+			s, _ := m[name].([]any)
+			i := indices[r]
+			r++
+			for len(s) <= i {
+				if leaf {
+					s = append(s, nil)
+				} else {
+					s = append(s, map[string]any{})
+				}
+			}
+			m[name] = s
+
+			if leaf {
+				s[i] = v
+				return
+			}
+			m = s[i].(map[string]any)
+			continue
+		}
+
+		if leaf {
+			m[name] = v
+			return
+		}
+
+		g, ok := m[name].(map[string]any)
+		if !ok {
+			g = map[string]any{}
+			m[name] = g
+		}
+		m = g
+	}
+}
+
+// DynamicWriter writes rows whose columns are described by a Schema.  It
+// has the same options as NewWriter and computes the definition and
+// repetition levels of each row at runtime.
+type DynamicWriter struct {
+	*Writer[Row]
+	schema *Schema
+}
+
+// NewDynamicWriter creates a DynamicWriter.  The file is the same as the
+// one written by the code that parquetgen generates for a struct with
+// the same columns.
+func NewDynamicWriter(w io.Writer, s *Schema, opts ...WriterOption) (*DynamicWriter, error) {
+	p, err := newWriter(w, s.columns, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(magic); err != nil {
+		p.spill.Close()
+		return nil, err
+	}
+	return &DynamicWriter{Writer: p, schema: s}, nil
+}
+
+// Add adds a row, which is either a map[string]any (or Row) keyed by the
+// names of the schema's nodes or a []any with a value for each node (in
+// order).  Groups are also a map[string]any or a []any, repeated nodes
+// are slices and null values are nil (or a nil pointer).  A row that
+// doesn't match the schema is rejected with a *RecordError.  Validators
+// (see WithValidator) get the row as a Row.
+func (p *DynamicWriter) Add(row any) error {
+	r, err := p.schema.row(row)
+	if err != nil {
+		return err
+	}
+	return p.Writer.Add(r)
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/parsyl/parquet"
+)
+
+// cat is the cat (and head) subcommand, which prints the rows of a
+// parquet file as JSON Lines or CSV:
+//
+//	parquetgen cat -format csv -columns id,address.city -offset 100 -limit 10 file.parquet
+//	parquetgen head file.parquet
+func cat(name string, args []string) error {
+	limit := int64(-1)
+	if name == "head" {
+		limit = 10
+	}
+
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	format := fs.String("format", "json", "format of the rows: json (JSON Lines) or csv")
+	columns := fs.String("columns", "", "comma separated list of the columns to print (the names of nested columns are joined with a '.'), defaults to all of them")
+	fs.Int64Var(&limit, "limit", limit, "maximum number of rows to print (-1 prints all of them)")
+	offset := fs.Int64("offset", 0, "number of rows to skip")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: parquetgen %s [-format json|csv] [-columns a,b.c] [-offset n] [-limit n] file.parquet\n", name)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var names []string
+	if *columns != "" {
+		names = strings.Split(*columns, ",")
+	}
+	return printRows(os.Stdout, f, *format, names, *offset, limit)
+}
+
+// column is a column (or group) that is printed by cat
+type column struct {
+	name string
+	path []string
+	node parquet.Node
+}
+
+// printRows decodes the rows of the file in r with the schema in its
+// footer and writes them to w.
+func printRows(w io.Writer, r io.ReadSeeker, format string, names []string, offset, limit int64) error {
+	footer, err := parquet.ReadMetaData(r)
+	if err != nil {
+		return fmt.Errorf("couldn't read footer: %s", err)
+	}
+
+	s, err := parquet.SchemaOf(footer)
+	if err != nil {
+		return err
+	}
+
+	cols, err := selectColumns(s.Nodes(), names)
+	if err != nil {
+		return err
+	}
+
+	var p rowPrinter
+	switch format {
+	case "json":
+		p = &jsonPrinter{w: w, cols: cols}
+	case "csv":
+		p = &csvPrinter{w: csv.NewWriter(w), cols: cols}
+	default:
+		return fmt.Errorf("unknown format %s (json or csv)", format)
+	}
+
+	if err := p.header(); err != nil {
+		return err
+	}
+
+	if len(names) > 0 {
+		// only the selected columns are read
+		paths := make([][]string, len(cols))
+		for i, c := range cols {
+			paths[i] = c.path
+		}
+
+		if s, err = parquet.NewSchema(prune(s.Nodes(), paths)...); err != nil {
+			return err
+		}
+	}
+
+	pr, err := parquet.NewCodecReader(r, s.Codec())
+	if err != nil {
+		return err
+	}
+
+	if err := pr.SeekToRow(min(max(offset, 0), pr.Rows())); err != nil {
+		return err
+	}
+
+	var n int64
+	for row, err := range pr.All() {
+		if err != nil {
+			return err
+		}
+
+		if limit >= 0 && n >= limit {
+			break
+		}
+
+		if err := p.row(row); err != nil {
+			return err
+		}
+		n++
+	}
+	return p.flush()
+}
+
+// selectColumns returns the columns called names (or all of the top
+// level nodes if there aren't any names).
+func selectColumns(nodes []parquet.Node, names []string) ([]column, error) {
+	if len(names) == 0 {
+		out := make([]column, len(nodes))
+		for i, n := range nodes {
+			out[i] = column{name: n.Name(), path: []string{n.Name()}, node: n}
+		}
+		return out, nil
+	}
+
+	out := make([]column, len(names))
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		pth := strings.Split(name, ".")
+		n, err := findNode(nodes, pth)
+		if err != nil {
+			return nil, fmt.Errorf("can't print column %s, err: %s", name, err)
+		}
+		out[i] = column{name: name, path: pth, node: n}
+	}
+	return out, nil
+}
+
+func findNode(nodes []parquet.Node, pth []string) (parquet.Node, error) {
+	for _, n := range nodes {
+		if n.Name() != pth[0] {
+			continue
+		}
+
+		if len(pth) == 1 {
+			return n, nil
+		}
+
+		if n.Kind() != reflect.Invalid {
+			return parquet.Node{}, fmt.Errorf("%s is not a group", n.Name())
+		}
+
+		if n.RepetitionType() == parquet.Repeated {
+			return parquet.Node{}, fmt.Errorf("%s is repeated (print the whole group instead)", n.Name())
+		}
+		return findNode(n.Children(), pth[1:])
+	}
+	return parquet.Node{}, fmt.Errorf("it is not in the file")
+}
+
+// prune returns the nodes at paths and the groups above them, which
+// only have the children that lead to one of the paths.
+func prune(nodes []parquet.Node, paths [][]string) []parquet.Node {
+	var out []parquet.Node
+	for _, n := range nodes {
+		var all bool
+		var children [][]string
+		for _, pth := range paths {
+			if pth[0] != n.Name() {
+				continue
+			}
+
+			if len(pth) == 1 {
+				all = true
+				break
+			}
+			children = append(children, pth[1:])
+		}
+
+		switch {
+		case all:
+			out = append(out, n)
+		case len(children) > 0:
+			out = append(out, withChildren(n, prune(n.Children(), children)))
+		}
+	}
+	return out
+}
+
+// withChildren returns a copy of the group n with different children
+func withChildren(n parquet.Node, children []parquet.Node) parquet.Node {
+	g := parquet.GroupNode(n.Name(), children...)
+	switch n.RepetitionType() {
+	case parquet.Optional:
+		g = g.Optional()
+	case parquet.Repeated:
+		g = g.Repeated()
+	}
+
+	if n.ID() != 0 {
+		g = g.WithID(n.ID())
+	}
+	return g.WithAnnotation(n.Annotation())
+}
+
+// value returns the value of c in row (nil if it or one of
+// its groups is null).
+func (c column) value(row parquet.Row) any {
+	m := map[string]any(row)
+	for _, name := range c.path[:len(c.path)-1] {
+		g, ok := m[name].(map[string]any)
+		if !ok {
+			return nil
+		}
+		m = g
+	}
+	return m[c.path[len(c.path)-1]]
+}
+
+type rowPrinter interface {
+	header() error
+	row(parquet.Row) error
+	flush() error
+}
+
+// jsonPrinter prints each row as a JSON object whose keys
+// are in the same order as the columns.
+type jsonPrinter struct {
+	w    io.Writer
+	cols []column
+	buf  bytes.Buffer
+}
+
+func (p *jsonPrinter) header() error { return nil }
+
+func (p *jsonPrinter) row(row parquet.Row) error {
+	p.buf.Reset()
+	p.buf.WriteByte('{')
+	for i, c := range p.cols {
+		if i > 0 {
+			p.buf.WriteByte(',')
+		}
+		writeJSONString(&p.buf, c.name)
+		p.buf.WriteByte(':')
+		writeJSON(&p.buf, c.node, c.value(row))
+	}
+	p.buf.WriteString("}\n")
+	_, err := p.w.Write(p.buf.Bytes())
+	return err
+}
+
+func (p *jsonPrinter) flush() error { return nil }
+
+// csvPrinter prints a header with the names of the columns and then
+// a record for each row.  Null values are empty and groups and repeated
+// fields are JSON.
+type csvPrinter struct {
+	w    *csv.Writer
+	cols []column
+	rec  []string
+	buf  bytes.Buffer
+}
+
+func (p *csvPrinter) header() error {
+	p.rec = make([]string, len(p.cols))
+	for i, c := range p.cols {
+		p.rec[i] = c.name
+	}
+	return p.w.Write(p.rec)
+}
+
+func (p *csvPrinter) row(row parquet.Row) error {
+	for i, c := range p.cols {
+		v := c.value(row)
+		switch {
+		case c.node.RepetitionType() == parquet.Repeated || (v != nil && c.node.Kind() == reflect.Invalid):
+			p.buf.Reset()
+			writeJSON(&p.buf, c.node, v)
+			p.rec[i] = p.buf.String()
+		case v == nil:
+			p.rec[i] = ""
+		default:
+			p.rec[i] = text(v)
+		}
+	}
+	return p.w.Write(p.rec)
+}
+
+func (p *csvPrinter) flush() error {
+	p.w.Flush()
+	return p.w.Error()
+}
+
+// writeJSON writes the value v of the node n: repeated nodes are
+// arrays and groups are objects whose keys are in the same order as
+// the group's children.
+func writeJSON(buf *bytes.Buffer, n parquet.Node, v any) {
+	if n.RepetitionType() == parquet.Repeated {
+		// an empty list is nil
+		s, _ := v.([]any)
+		buf.WriteByte('[')
+		for i, x := range s {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeElement(buf, n, x)
+		}
+		buf.WriteByte(']')
+		return
+	}
+	writeElement(buf, n, v)
+}
+
+func writeElement(buf *bytes.Buffer, n parquet.Node, v any) {
+	if v == nil {
+		buf.WriteString("null")
+		return
+	}
+
+	if n.Kind() != reflect.Invalid {
+		writeJSONValue(buf, v)
+		return
+	}
+
+	m := v.(map[string]any)
+	buf.WriteByte('{')
+	for i, c := range n.Children() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONString(buf, c.Name())
+		buf.WriteByte(':')
+		writeJSON(buf, c, m[c.Name()])
+	}
+	buf.WriteByte('}')
+}
+
+// writeJSONValue writes the value of a column.  Floats that
+// can't be represented by JSON (NaN and ±Inf) are strings.
+func writeJSONValue(buf *bytes.Buffer, v any) {
+	switch x := v.(type) {
+	case string:
+		writeJSONString(buf, x)
+	case float32, float64:
+		f := reflect.ValueOf(x).Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			writeJSONString(buf, text(x))
+			return
+		}
+		buf.WriteString(text(x))
+	default:
+		buf.WriteString(text(x))
+	}
+}
+
+// text is the value of a column as a string
+func text(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	// Encode adds a newline
+	buf.Truncate(buf.Len() - 1)
+}
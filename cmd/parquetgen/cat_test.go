@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/parsyl/parquet"
+	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/logical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintRows(t *testing.T) {
+	s, err := parquet.NewSchema(
+		parquet.Int64Node("id"),
+		parquet.StringNode("name").Optional(),
+		parquet.Float64Node("score").Optional(),
+		parquet.GroupNode("address",
+			parquet.StringNode("city"),
+			parquet.Int32Node("zip").Optional(),
+		).Optional(),
+		parquet.GroupNode("friends",
+			parquet.Int64Node("id"),
+			parquet.StringNode("tags").Repeated(),
+		).Repeated(),
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := parquet.NewDynamicWriter(&buf, s, parquet.MaxPageSize(2))
+	require.NoError(t, err)
+	rows := [][]any{
+		{1, "a <b>", 1.5, []any{"Boise", 83702}, []any{[]any{2, []string{"x", "y"}}, []any{3, nil}}},
+		{2, nil, math.NaN(), nil, nil},
+		{3, "c,d", 0.25, []any{"Reno", nil}, []any{[]any{1, []string{"z"}}}},
+	}
+	for i, row := range rows {
+		require.NoError(t, w.Add(row))
+		if i == 1 {
+			require.NoError(t, w.Write())
+		}
+	}
+	require.NoError(t, w.Write())
+	require.NoError(t, w.Close())
+
+	testCases := []struct {
+		name     string
+		format   string
+		columns  []string
+		offset   int64
+		limit    int64
+		expected string
+		err      string
+	}{
+		{
+			name:   "json",
+			format: "json",
+			limit:  -1,
+			expected: `{"id":1,"name":"a <b>","score":1.5,"address":{"city":"Boise","zip":83702},"friends":[{"id":2,"tags":["x","y"]},{"id":3,"tags":[]}]}
+{"id":2,"name":null,"score":"NaN","address":null,"friends":[]}
+{"id":3,"name":"c,d","score":0.25,"address":{"city":"Reno","zip":null},"friends":[{"id":1,"tags":["z"]}]}
+`,
+		},
+		{
+			name:    "json columns offset limit",
+			format:  "json",
+			columns: []string{"address.zip", "id"},
+			offset:  1,
+			limit:   1,
+			expected: `{"address.zip":null,"id":2}
+`,
+		},
+		{
+			name:    "csv",
+			format:  "csv",
+			columns: []string{"id", "name", "address.city", "friends"},
+			offset:  1,
+			limit:   5,
+			expected: `id,name,address.city,friends
+2,,,[]
+3,"c,d",Reno,"[{""id"":1,""tags"":[""z""]}]"
+`,
+		},
+		{
+			name:     "offset past the end",
+			format:   "csv",
+			columns:  []string{"id"},
+			offset:   10,
+			limit:    -1,
+			expected: "id\n",
+		},
+		{
+			name:    "unknown column",
+			format:  "json",
+			columns: []string{"address.street"},
+			err:     "can't print column address.street, err: it is not in the file",
+		},
+		{
+			name:    "repeated group",
+			format:  "json",
+			columns: []string{"friends.id"},
+			err:     "can't print column friends.id, err: friends is repeated (print the whole group instead)",
+		},
+		{
+			name:   "unknown format",
+			format: "xml",
+			err:    "unknown format xml (json or csv)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := printRows(&out, bytes.NewReader(buf.Bytes()), tc.format, tc.columns, tc.offset, tc.limit)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
+
+func TestPrintRowsReadsSelectedColumns(t *testing.T) {
+	s, err := parquet.NewSchema(
+		parquet.Int64Node("id"),
+		parquet.StringNode("name").Optional(),
+		parquet.GroupNode("address",
+			parquet.StringNode("city"),
+			parquet.Int32Node("zip").Optional(),
+		).Optional(),
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := parquet.NewDynamicWriter(&buf, s)
+	require.NoError(t, err)
+	require.NoError(t, w.Add([]any{1, "a", []any{"Boise", 83702}}))
+	require.NoError(t, w.Add([]any{2, "b", nil}))
+	require.NoError(t, w.Write())
+	require.NoError(t, w.Close())
+
+	// the pages of name and address.zip can't be decoded
+	b := buf.Bytes()
+	footer, err := parquet.ReadMetaData(bytes.NewReader(b))
+	require.NoError(t, err)
+	for _, i := range []int{1, 3} {
+		md := footer.RowGroups[0].Columns[i].MetaData
+		copy(b[md.DataPageOffset:md.DataPageOffset+md.TotalCompressedSize], bytes.Repeat([]byte{0xff}, int(md.TotalCompressedSize)))
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, printRows(&out, bytes.NewReader(b), "json", []string{"address.city", "id"}, 0, -1))
+	assert.Equal(t, `{"address.city":"Boise","id":1}
+{"address.city":null,"id":2}
+`, out.String())
+
+	assert.Error(t, printRows(io.Discard, bytes.NewReader(b), "json", nil, 0, -1))
+}
+
+func TestPrintRowsLogicalTypes(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := logical.NewParquetWriter(&buf)
+	require.NoError(t, err)
+	due := int32(19000)
+	require.NoError(t, w.Add(logical.Invoice{Id: 1, Total: 1050, Due: &due, Customer: "a", Lines: &logical.Lines{List: []logical.List{{Element: "x"}}}}))
+	require.NoError(t, w.Add(logical.Invoice{Id: 2, Total: 25, Customer: "b"}))
+	require.NoError(t, w.Write())
+	require.NoError(t, w.Close())
+
+	var out bytes.Buffer
+	require.NoError(t, printRows(&out, bytes.NewReader(buf.Bytes()), "json", nil, 0, -1))
+	assert.Equal(t, `{"id":1,"total":1050,"due":19000,"customer":"a","lines":{"list":[{"element":"x"}]}}
+{"id":2,"total":25,"due":null,"customer":"b","lines":null}
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, printRows(&out, bytes.NewReader(buf.Bytes()), "json", []string{"total", "lines.list"}, 0, -1))
+	assert.Equal(t, `{"total":1050,"lines.list":[{"element":"x"}]}
+{"total":25,"lines.list":[]}
+`, out.String())
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/parsyl/parquet"
 	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/doc"
+	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/orders"
 	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/person"
 	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/repetition"
 	"github.com/stretchr/testify/assert"
@@ -74,7 +76,7 @@ func TestLevels(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
-	expected := []doc.Levels{
+	expected := []parquet.Levels{
 		{Name: "docid"},
 		{Name: "link.backward", Defs: []uint8{1, 2, 2}, Reps: []uint8{0, 0, 1}},
 		{Name: "link.forward", Defs: []uint8{2, 2, 2, 2}, Reps: []uint8{0, 1, 1, 0}},
@@ -124,7 +126,7 @@ func TestPersonLevels(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
-	expected := []person.Levels{
+	expected := []parquet.Levels{
 		{Name: "name"},
 		{Name: "hobby.name", Defs: []uint8{1}},
 		{Name: "hobby.difficulty", Defs: []uint8{2}},
@@ -277,3 +279,73 @@ func TestRepetition(t *testing.T) {
 
 	assert.Equal(t, repetitionDocs, out)
 }
+
+// TestMultipleTypes verifies the code that is generated for more
+// than one struct in a package (-type Customer,Order).
+func TestMultipleTypes(t *testing.T) {
+	customers := []orders.Customer{
+		{ID: 1, Name: "a", Email: pstring("a@example.com")},
+		{ID: 2, Name: "b"},
+	}
+
+	ords := []orders.Order{
+		{ID: 10, CustomerID: 1, Items: []orders.Item{{Name: "x", Quantity: 2}, {Name: "y", Quantity: 1}}, Total: 3.5},
+		{ID: 11, CustomerID: 2, Total: 0},
+	}
+
+	var cbuf bytes.Buffer
+	cw, err := orders.NewCustomerParquetWriter(&cbuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range customers {
+		assert.NoError(t, cw.Add(c))
+	}
+	assert.NoError(t, cw.Write())
+	assert.NoError(t, cw.Close())
+
+	var obuf bytes.Buffer
+	ow, err := orders.NewOrderParquetWriter(&obuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, o := range ords {
+		assert.NoError(t, ow.Add(o))
+	}
+	assert.NoError(t, ow.Write())
+	assert.NoError(t, ow.Close())
+
+	cr, err := orders.NewCustomerParquetReader(bytes.NewReader(cbuf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cout []orders.Customer
+	for cr.Next() {
+		var c orders.Customer
+		cr.Scan(&c)
+		cout = append(cout, c)
+	}
+	assert.NoError(t, cr.Error())
+	assert.Equal(t, customers, cout)
+
+	or, err := orders.NewOrderParquetReader(bytes.NewReader(obuf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var oout []orders.Order
+	for or.Next() {
+		var o orders.Order
+		or.Scan(&o)
+		oout = append(oout, o)
+	}
+	assert.NoError(t, or.Error())
+	assert.Equal(t, ords, oout)
+
+	// the Order schema doesn't match a file of customers
+	_, err = orders.NewOrderParquetReader(bytes.NewReader(cbuf.Bytes()), parquet.StrictSchema)
+	assert.Error(t, err)
+}
@@ -10,7 +10,7 @@ import (
 func readRequired(f fields.Field) string {
 	return fmt.Sprintf(`func read%s(x %s) %s {
 	return x.%s
-}`, strings.Join(f.FieldNames(), ""), f.StructType(), f.TypeName(), strings.Join(f.FieldNames(), "."))
+}`, f.FuncName(), f.StructType(), f.TypeName(), strings.Join(f.FieldNames(), "."))
 }
 
 func readOptional(f fields.Field) string {
@@ -38,7 +38,7 @@ func readOptional(f fields.Field) string {
 		switch {
 		%s
 		}
-	}`, strings.Join(f.FieldNames(), ""), f.StructType(), cleanTypeName(f.Type), cleanTypeName(f.Type), out)
+	}`, f.FuncName(), f.StructType(), cleanTypeName(f.Type), cleanTypeName(f.Type), out)
 }
 
 func cleanTypeName(s string) string {
@@ -67,62 +67,49 @@ func readRepeated(f fields.Field) string {
 
 	return vals, defs, reps	
 }`,
-		strings.Join(f.FieldNames(), ""),
+		f.FuncName(),
 		f.StructType(),
 		cleanTypeName(f.Type),
 		cleanTypeName(f.Type),
-		doReadRepeated(f, 0, "x"),
+		doReadRepeated(f, 0, 0, "x"),
 	)
 }
 
-func doReadRepeated(f fields.Field, i int, varName string) string {
+// doReadRepeated writes the clause for the definition level i.  varName
+// is the struct (or the element of a repeated field) that the names of
+// the fields from start on are read from.
+func doReadRepeated(f fields.Field, i, start int, varName string) string {
 	if i == f.MaxDef() {
 		rts := f.RepetitionTypes()
 		if rts[len(rts)-1] == fields.Optional {
 			varName = fmt.Sprintf("*%s", varName)
 		}
 		if rts[len(rts)-1] != fields.Repeated {
-			n := lastRepeated(rts)
-			varName = strings.Join(append([]string{varName}, f.FieldNames()[n+1:]...), ".")
+			varName = strings.Join(append([]string{varName}, f.FieldNames()[start:]...), ".")
 		}
 		return fmt.Sprintf(`defs = append(defs, %d)
 reps = append(reps, lastRep)
 vals = append(vals, %s)`, i, varName)
 	}
 
-	fieldName, rt, n, reps := f.NilField(i)
+	_, rt, n, reps := f.NilField(i)
 	var nextVar string
 	var buf bytes.Buffer
 	rc := readClause{
 		Var:   varName,
-		Field: fieldName,
+		Field: strings.Join(f.FieldNames()[start:n+1], "."),
 		Rep:   reps - 1,
 		Def:   i,
 	}
 
 	if rt == fields.Repeated {
-		if reps > 1 {
-			rc.Field = f.FieldNames()[n]
-		}
 		nextVar = fmt.Sprintf("x%d", reps-1)
+		start = n + 1
 		readRepeatedRepeatedTpl.Execute(&buf, rc)
 	} else {
 		nextVar = varName
-		if reps > 0 {
-			rc.Field = strings.Join(f.FieldNames()[i:], ".")
-		}
 		readRepeatedOptionalTpl.Execute(&buf, rc)
 	}
 
-	return fmt.Sprintf(string(buf.Bytes()), doReadRepeated(f, i+1, nextVar))
-}
-
-func lastRepeated(rts []fields.RepetitionType) int {
-	var l int
-	for i, rt := range rts {
-		if rt == fields.Repeated {
-			l = i
-		}
-	}
-	return l
+	return fmt.Sprintf(string(buf.Bytes()), doReadRepeated(f, i+1, start, nextVar))
 }
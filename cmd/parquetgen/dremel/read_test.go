@@ -576,6 +576,58 @@ func TestRead(t *testing.T) {
 		}
 	}
 
+	return vals, defs, reps
+}`,
+		},
+		{
+			name: "optional list in a repeated group",
+			f: fields.Field{
+				Name: "Friends", Type: "Friend", RepetitionType: fields.Repeated, Children: []fields.Field{
+					{Name: "ID", Type: "int64", RepetitionType: fields.Required},
+					{Name: "Tags", Type: "Tags", RepetitionType: fields.Optional, Children: []fields.Field{
+						{Name: "List", Type: "Item", RepetitionType: fields.Repeated, Children: []fields.Field{
+							{Name: "Element", Type: "string", RepetitionType: fields.Optional},
+						}},
+					}},
+				},
+			},
+			result: `func readFriendsTagsListElement(x Person, vals []string, defs, reps []uint8) ([]string, []uint8, []uint8) {
+	var lastRep uint8
+
+	if len(x.Friends) == 0 {
+		defs = append(defs, 0)
+		reps = append(reps, lastRep)
+	} else {
+		for i0, x0 := range x.Friends {
+			if i0 >= 1 {
+				lastRep = 1
+			}
+			if x0.Tags == nil {
+				defs = append(defs, 1)
+				reps = append(reps, lastRep)
+			} else {
+				if len(x0.Tags.List) == 0 {
+					defs = append(defs, 2)
+					reps = append(reps, lastRep)
+				} else {
+					for i1, x1 := range x0.Tags.List {
+						if i1 >= 1 {
+							lastRep = 2
+						}
+						if x1.Element == nil {
+							defs = append(defs, 3)
+							reps = append(reps, lastRep)
+						} else {
+							defs = append(defs, 4)
+							reps = append(reps, lastRep)
+							vals = append(vals, *x1.Element)
+						}
+					}
+				}
+			}
+		}
+	}
+
 	return vals, defs, reps
 }`,
 		},
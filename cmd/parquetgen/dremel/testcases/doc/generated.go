@@ -1,58 +1,54 @@
+// Code generated by github.com/parsyl/parquet. DO NOT EDIT.
 package doc
 
-// Code generated by github.com/parsyl/parquet.  DO NOT EDIT.
-
 import (
-	"encoding/binary"
-	"fmt"
 	"io"
-	"math"
-	"strings"
 
 	"github.com/parsyl/parquet"
 	sch "github.com/parsyl/parquet/schema"
-	"github.com/valyala/bytebufferpool"
-)
-
-var _ = math.MaxInt32 // to avoid unused import
-
-type compression int
-
-const (
-	compressionUncompressed compression = 0
-	compressionSnappy       compression = 1
-	compressionGzip         compression = 2
-	compressionUnknown      compression = -1
 )
 
-var buffpool = bytebufferpool.Pool{}
+// ParquetWriter writes Document records to a parquet file
+type ParquetWriter = parquet.Writer[Document]
 
-// ParquetWriter reprents a row group
-type ParquetWriter struct {
-	fields []Field
+// ParquetReader reads Document records from a parquet file
+type ParquetReader = parquet.Reader[Document]
 
-	len int
+// NewParquetWriter creates a writer for Document records
+func NewParquetWriter(w io.Writer, opts ...parquet.WriterOption) (*ParquetWriter, error) {
+	return parquet.NewCodecWriter[Document](w, DocumentCodec{}, opts...)
+}
 
-	// child points to the next page
-	child *ParquetWriter
+// OpenForAppend creates a writer that adds row groups to the parquet file
+// in rws, which must have the schema of Document (see parquet.OpenForAppend).
+func OpenForAppend(rws io.ReadWriteSeeker, opts ...parquet.WriterOption) (*ParquetWriter, error) {
+	return parquet.OpenCodecForAppend[Document](rws, DocumentCodec{}, opts...)
+}
 
-	// max is the number of Record items that can get written before
-	// a new set of column chunks is written
-	max int
+// NewParquetReader creates a reader for Document records
+func NewParquetReader(r io.ReadSeeker, opts ...parquet.ReaderOption) (*ParquetReader, error) {
+	return parquet.NewCodecReader[Document](r, DocumentCodec{}, opts...)
+}
 
-	meta        *parquet.Metadata
-	w           io.Writer
-	compression compression
+// NewParquetReaderAt creates a reader for the parquet file of the given size.
+// The file is only read with ReadAt so several readers (for example, each one
+// reading a different row group) can share r from different goroutines.
+func NewParquetReaderAt(r io.ReaderAt, size int64, opts ...parquet.ReaderOption) (*ParquetReader, error) {
+	return parquet.NewCodecReaderAt[Document](r, size, DocumentCodec{}, opts...)
 }
 
-func Fields(compression compression) []Field {
-	return []Field{
-		NewInt64Field(readDocID, writeDocID, []string{"docid"}, fieldCompression(compression)),
-		NewInt64OptionalField(readLinksBackward, writeLinksBackward, []string{"link", "backward"}, []int{1, 2}, optionalFieldCompression(compression)),
-		NewInt64OptionalField(readLinksForward, writeLinksForward, []string{"link", "forward"}, []int{1, 2}, optionalFieldCompression(compression)),
-		NewStringOptionalField(readNamesLanguagesCode, writeNamesLanguagesCode, []string{"names", "languages", "code"}, []int{2, 2, 0}, optionalFieldCompression(compression)),
-		NewStringOptionalField(readNamesLanguagesCountry, writeNamesLanguagesCountry, []string{"names", "languages", "country"}, []int{2, 2, 1}, optionalFieldCompression(compression)),
-		NewStringOptionalField(readNamesURL, writeNamesURL, []string{"names", "url"}, []int{2, 1}, optionalFieldCompression(compression)),
+// DocumentCodec is the parquet.Codec of Document
+type DocumentCodec struct{}
+
+// Fields creates the columns of Document
+func (DocumentCodec) Fields(c sch.CompressionCodec) []parquet.Column[Document] {
+	return []parquet.Column[Document]{
+		parquet.NewRequiredColumn(readDocID, writeDocID, []string{"docid"}, parquet.RequiredFieldCompression(c)),
+		parquet.NewOptionalColumn(readLinksBackward, writeLinksBackward, []string{"link", "backward"}, []int{1, 2}, parquet.OptionalFieldCompression(c)),
+		parquet.NewOptionalColumn(readLinksForward, writeLinksForward, []string{"link", "forward"}, []int{1, 2}, parquet.OptionalFieldCompression(c)),
+		parquet.NewOptionalColumn(readNamesLanguagesCode, writeNamesLanguagesCode, []string{"names", "languages", "code"}, []int{2, 2, 0}, parquet.OptionalFieldCompression(c)),
+		parquet.NewOptionalColumn(readNamesLanguagesCountry, writeNamesLanguagesCountry, []string{"names", "languages", "country"}, []int{2, 2, 1}, parquet.OptionalFieldCompression(c)),
+		parquet.NewOptionalColumn(readNamesURL, writeNamesURL, []string{"names", "url"}, []int{2, 1}, parquet.OptionalFieldCompression(c)),
 	}
 }
 
@@ -91,7 +87,7 @@ func readLinksBackward(x Document, vals []int64, defs, reps []uint8) ([]int64, [
 
 func writeLinksBackward(x *Document, vals []int64, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -101,7 +97,7 @@ func writeLinksBackward(x *Document, vals []int64, defs, reps []uint8) (int, int
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -147,7 +143,7 @@ func readLinksForward(x Document, vals []int64, defs, reps []uint8) ([]int64, []
 
 func writeLinksForward(x *Document, vals []int64, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -157,7 +153,7 @@ func writeLinksForward(x *Document, vals []int64, defs, reps []uint8) (int, int)
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -201,7 +197,7 @@ func readNamesLanguagesCode(x Document, vals []string, defs, reps []uint8) ([]st
 
 func writeNamesLanguagesCode(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 2)
+	ind := make(parquet.Indices, 2)
 
 	for i := range defs {
 		def := defs[i]
@@ -211,7 +207,7 @@ func writeNamesLanguagesCode(x *Document, vals []string, defs, reps []uint8) (in
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -267,7 +263,7 @@ func readNamesLanguagesCountry(x Document, vals []string, defs, reps []uint8) ([
 
 func writeNamesLanguagesCountry(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 2)
+	ind := make(parquet.Indices, 2)
 
 	for i := range defs {
 		def := defs[i]
@@ -277,11 +273,11 @@ func writeNamesLanguagesCountry(x *Document, vals []string, defs, reps []uint8)
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 3:
-			x.Names[ind[0]].Languages[ind[1]].Country = pstring(vals[nVals])
+			x.Names[ind[0]].Languages[ind[1]].Country = parquet.Ptr(vals[nVals])
 			nVals++
 		}
 	}
@@ -316,7 +312,7 @@ func readNamesURL(x Document, vals []string, defs, reps []uint8) ([]string, []ui
 
 func writeNamesURL(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -326,802 +322,14 @@ func writeNamesURL(x *Document, vals []string, defs, reps []uint8) (int, int) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
-			x.Names[ind[0]].URL = pstring(vals[nVals])
+			x.Names[ind[0]].URL = parquet.Ptr(vals[nVals])
 			nVals++
 		}
 	}
 
 	return nVals, nLevels
 }
-
-func fieldCompression(c compression) func(*parquet.RequiredField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.RequiredFieldUncompressed
-	case compressionSnappy:
-		return parquet.RequiredFieldSnappy
-	case compressionGzip:
-		return parquet.RequiredFieldGzip
-	default:
-		return parquet.RequiredFieldUncompressed
-	}
-}
-
-func optionalFieldCompression(c compression) func(*parquet.OptionalField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.OptionalFieldUncompressed
-	case compressionSnappy:
-		return parquet.OptionalFieldSnappy
-	case compressionGzip:
-		return parquet.OptionalFieldGzip
-	default:
-		return parquet.OptionalFieldUncompressed
-	}
-}
-
-func NewParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
-	return newParquetWriter(w, append(opts, begin)...)
-}
-
-func newParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
-	p := &ParquetWriter{
-		max:         1000,
-		w:           w,
-		compression: compressionSnappy,
-	}
-
-	for _, opt := range opts {
-		if err := opt(p); err != nil {
-			return nil, err
-		}
-	}
-
-	p.fields = Fields(p.compression)
-	if p.meta == nil {
-		ff := Fields(p.compression)
-		schema := make([]parquet.Field, len(ff))
-		for i, f := range ff {
-			schema[i] = f.Schema()
-		}
-		p.meta = parquet.New(schema...)
-	}
-
-	return p, nil
-}
-
-// MaxPageSize is the maximum number of rows in each row groups' page.
-func MaxPageSize(m int) func(*ParquetWriter) error {
-	return func(p *ParquetWriter) error {
-		p.max = m
-		return nil
-	}
-}
-
-var par1 = []byte("PAR1")
-
-func begin(p *ParquetWriter) error {
-	_, err := p.w.Write(par1)
-	return err
-}
-
-func withMeta(m *parquet.Metadata) func(*ParquetWriter) error {
-	return func(p *ParquetWriter) error {
-		p.meta = m
-		return nil
-	}
-}
-
-func Uncompressed(p *ParquetWriter) error {
-	p.compression = compressionUncompressed
-	return nil
-}
-
-func Snappy(p *ParquetWriter) error {
-	p.compression = compressionSnappy
-	return nil
-}
-
-func Gzip(p *ParquetWriter) error {
-	p.compression = compressionGzip
-	return nil
-}
-
-func withCompression(c compression) func(*ParquetWriter) error {
-	return func(p *ParquetWriter) error {
-		p.compression = c
-		return nil
-	}
-}
-
-func (p *ParquetWriter) Write() error {
-	for i, f := range p.fields {
-		if err := f.Write(p.w, p.meta); err != nil {
-			return err
-		}
-
-		for child := p.child; child != nil; child = child.child {
-			if err := child.fields[i].Write(p.w, p.meta); err != nil {
-				return err
-			}
-		}
-	}
-
-	p.fields = Fields(p.compression)
-	p.child = nil
-	p.len = 0
-
-	schema := make([]parquet.Field, len(p.fields))
-	for i, f := range p.fields {
-		schema[i] = f.Schema()
-	}
-	p.meta.StartRowGroup(schema...)
-	return nil
-}
-
-func (p *ParquetWriter) Close() error {
-	if err := p.meta.Footer(p.w); err != nil {
-		return err
-	}
-
-	_, err := p.w.Write(par1)
-	return err
-}
-
-func (p *ParquetWriter) Add(rec Document) {
-	if p.len == p.max {
-		if p.child == nil {
-			// an error can't happen here
-			p.child, _ = newParquetWriter(p.w, MaxPageSize(p.max), withMeta(p.meta), withCompression(p.compression))
-		}
-
-		p.child.Add(rec)
-		return
-	}
-
-	p.meta.NextDoc()
-	for _, f := range p.fields {
-		f.Add(rec)
-	}
-
-	p.len++
-}
-
-type Field interface {
-	Add(r Document)
-	Write(w io.Writer, meta *parquet.Metadata) error
-	Schema() parquet.Field
-	Scan(r *Document)
-	Read(r io.ReadSeeker, pg parquet.Page) error
-	Name() string
-	Levels() ([]uint8, []uint8)
-}
-
-func getFields(ff []Field) map[string]Field {
-	m := make(map[string]Field, len(ff))
-	for _, f := range ff {
-		m[f.Name()] = f
-	}
-	return m
-}
-
-func NewParquetReader(r io.ReadSeeker, opts ...func(*ParquetReader)) (*ParquetReader, error) {
-	ff := Fields(compressionUnknown)
-	pr := &ParquetReader{
-		r: r,
-	}
-
-	for _, opt := range opts {
-		opt(pr)
-	}
-
-	schema := make([]parquet.Field, len(ff))
-	for i, f := range ff {
-		pr.fieldNames = append(pr.fieldNames, f.Name())
-		schema[i] = f.Schema()
-	}
-
-	meta := parquet.New(schema...)
-	if err := meta.ReadFooter(r); err != nil {
-		return nil, err
-	}
-	pr.rows = meta.Rows()
-	var err error
-	pr.pages, err = meta.Pages()
-	if err != nil {
-		return nil, err
-	}
-
-	pr.rowGroups = meta.RowGroups()
-	_, err = r.Seek(4, io.SeekStart)
-	if err != nil {
-		return nil, err
-	}
-	pr.meta = meta
-
-	return pr, pr.readRowGroup()
-}
-
-func readerIndex(i int) func(*ParquetReader) {
-	return func(p *ParquetReader) {
-		p.index = i
-	}
-}
-
-// ParquetReader reads one page from a row group.
-type ParquetReader struct {
-	fields         map[string]Field
-	fieldNames     []string
-	index          int
-	cursor         int64
-	rows           int64
-	rowGroupCursor int64
-	rowGroupCount  int64
-	pages          map[string][]parquet.Page
-	meta           *parquet.Metadata
-	err            error
-
-	r         io.ReadSeeker
-	rowGroups []parquet.RowGroup
-}
-
-type Levels struct {
-	Name string
-	Defs []uint8
-	Reps []uint8
-}
-
-func (p *ParquetReader) Levels() []Levels {
-	var out []Levels
-	//for {
-	for _, name := range p.fieldNames {
-		f := p.fields[name]
-		d, r := f.Levels()
-		out = append(out, Levels{Name: f.Name(), Defs: d, Reps: r})
-	}
-	//	if err := p.readRowGroup(); err != nil {
-	//		break
-	//	}
-	//}
-	return out
-}
-
-func (p *ParquetReader) Error() error {
-	return p.err
-}
-
-func (p *ParquetReader) readRowGroup() error {
-	p.rowGroupCursor = 0
-
-	if len(p.rowGroups) == 0 {
-		p.rowGroupCount = 0
-		return nil
-	}
-
-	rg := p.rowGroups[0]
-	p.fields = getFields(Fields(compressionUnknown))
-	p.rowGroupCount = rg.Rows
-	p.rowGroupCursor = 0
-	for _, col := range rg.Columns() {
-		name := strings.Join(col.MetaData.PathInSchema, ".")
-		f, ok := p.fields[name]
-		if !ok {
-			return fmt.Errorf("unknown field: %s", name)
-		}
-		pages := p.pages[name]
-		if len(pages) <= p.index {
-			break
-		}
-
-		pg := pages[0]
-		if err := f.Read(p.r, pg); err != nil {
-			return fmt.Errorf("unable to read field %s, err: %s", f.Name(), err)
-		}
-		p.pages[name] = p.pages[name][1:]
-	}
-	p.rowGroups = p.rowGroups[1:]
-	return nil
-}
-
-func (p *ParquetReader) Rows() int64 {
-	return p.rows
-}
-
-func (p *ParquetReader) Next() bool {
-	if p.err == nil && p.cursor >= p.rows {
-		return false
-	}
-	if p.rowGroupCursor >= p.rowGroupCount {
-		p.err = p.readRowGroup()
-		if p.err != nil {
-			return false
-		}
-	}
-
-	p.cursor++
-	p.rowGroupCursor++
-	return true
-}
-
-func (p *ParquetReader) Scan(x *Document) {
-	if p.err != nil {
-		return
-	}
-
-	for _, name := range p.fieldNames {
-		f := p.fields[name]
-		f.Scan(x)
-	}
-}
-
-type Int64Field struct {
-	vals []int64
-	parquet.RequiredField
-	read  func(r Document) int64
-	write func(r *Document, vals []int64)
-	stats *int64stats
-}
-
-func NewInt64Field(read func(r Document) int64, write func(r *Document, vals []int64), path []string, opts ...func(*parquet.RequiredField)) *Int64Field {
-	return &Int64Field{
-		read:          read,
-		write:         write,
-		RequiredField: parquet.NewRequiredField(path, opts...),
-		stats:         newInt64stats(),
-	}
-}
-
-func (f *Int64Field) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Int64Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
-}
-
-func (f *Int64Field) Read(r io.ReadSeeker, pg parquet.Page) error {
-	rr, _, err := f.DoRead(r, pg)
-	if err != nil {
-		return err
-	}
-
-	v := make([]int64, int(pg.N))
-	err = binary.Read(rr, binary.LittleEndian, &v)
-	f.vals = append(f.vals, v...)
-	return err
-}
-
-func (f *Int64Field) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
-
-	bs := make([]byte, 8)
-	for _, v := range f.vals {
-		binary.LittleEndian.PutUint64(bs, uint64(v))
-		if _, err := buf.Write(bs); err != nil {
-			return err
-		}
-	}
-	return f.DoWrite(w, meta, buf.Bytes(), len(f.vals), f.stats)
-}
-
-func (f *Int64Field) Scan(r *Document) {
-	if len(f.vals) == 0 {
-		return
-	}
-
-	f.write(r, f.vals)
-	f.vals = f.vals[1:]
-}
-
-func (f *Int64Field) Add(r Document) {
-	v := f.read(r)
-	f.stats.add(v)
-	f.vals = append(f.vals, v)
-}
-
-func (f *Int64Field) Levels() ([]uint8, []uint8) {
-	return nil, nil
-}
-
-type Int64OptionalField struct {
-	parquet.OptionalField
-	vals  []int64
-	read  func(r Document, vals []int64, defs, reps []uint8) ([]int64, []uint8, []uint8)
-	write func(r *Document, vals []int64, defs, reps []uint8) (int, int)
-	stats *int64optionalStats
-}
-
-func NewInt64OptionalField(read func(r Document, vals []int64, defs, reps []uint8) ([]int64, []uint8, []uint8), write func(r *Document, vals []int64, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *Int64OptionalField {
-	return &Int64OptionalField{
-		read:          read,
-		write:         write,
-		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newint64optionalStats(maxDef(types)),
-	}
-}
-
-func (f *Int64OptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Int64Type, RepetitionType: f.RepetitionType, Types: f.Types}
-}
-
-func (f *Int64OptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
-
-	bs := make([]byte, 8)
-	for _, v := range f.vals {
-		binary.LittleEndian.PutUint64(bs, uint64(v))
-		if _, err := buf.Write(bs); err != nil {
-			return err
-		}
-	}
-	return f.DoWrite(w, meta, buf.Bytes(), len(f.Defs), f.stats)
-}
-
-func (f *Int64OptionalField) Read(r io.ReadSeeker, pg parquet.Page) error {
-	rr, _, err := f.DoRead(r, pg)
-	if err != nil {
-		return err
-	}
-
-	v := make([]int64, f.Values()-len(f.vals))
-	err = binary.Read(rr, binary.LittleEndian, &v)
-	f.vals = append(f.vals, v...)
-	return err
-}
-
-func (f *Int64OptionalField) Add(r Document) {
-	vals, defs, reps := f.read(r, f.vals, f.Defs, f.Reps)
-	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
-	f.vals = vals
-	f.Defs = defs
-	f.Reps = reps
-}
-
-func (f *Int64OptionalField) Scan(r *Document) {
-	if len(f.Defs) == 0 {
-		return
-	}
-
-	v, l := f.write(r, f.vals, f.Defs, f.Reps)
-	f.vals = f.vals[v:]
-	f.Defs = f.Defs[l:]
-	if len(f.Reps) > 0 {
-		f.Reps = f.Reps[l:]
-	}
-}
-
-func (f *Int64OptionalField) Levels() ([]uint8, []uint8) {
-	return f.Defs, f.Reps
-}
-
-type StringOptionalField struct {
-	parquet.OptionalField
-	vals  []string
-	read  func(r Document, vals []string, def, rep []uint8) ([]string, []uint8, []uint8)
-	write func(r *Document, vals []string, def, rep []uint8) (int, int)
-	stats *stringOptionalStats
-}
-
-func NewStringOptionalField(read func(r Document, vals []string, def, rep []uint8) ([]string, []uint8, []uint8), write func(r *Document, vals []string, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *StringOptionalField {
-	return &StringOptionalField{
-		read:          read,
-		write:         write,
-		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newStringOptionalStats(maxDef(types)),
-	}
-}
-
-func (f *StringOptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: StringType, RepetitionType: f.RepetitionType, Types: f.Types}
-}
-
-func (f *StringOptionalField) Add(r Document) {
-	vals, defs, reps := f.read(r, f.vals, f.Defs, f.Reps)
-	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
-	f.vals = vals
-	f.Defs = defs
-	f.Reps = reps
-}
-
-func (f *StringOptionalField) Scan(r *Document) {
-	if len(f.Defs) == 0 {
-		return
-	}
-
-	v, l := f.write(r, f.vals, f.Defs, f.Reps)
-	f.vals = f.vals[v:]
-	f.Defs = f.Defs[l:]
-	if len(f.Reps) > 0 {
-		f.Reps = f.Reps[l:]
-	}
-}
-
-func (f *StringOptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
-
-	bs := make([]byte, 4)
-	for _, s := range f.vals {
-		binary.LittleEndian.PutUint32(bs, uint32(len(s)))
-		if _, err := buf.Write(bs); err != nil {
-			return err
-		}
-		buf.WriteString(s)
-	}
-
-	return f.DoWrite(w, meta, buf.Bytes(), len(f.Defs), f.stats)
-}
-
-func (f *StringOptionalField) Read(r io.ReadSeeker, pg parquet.Page) error {
-	rr, _, err := f.DoRead(r, pg)
-	if err != nil {
-		return err
-	}
-
-	for j := 0; j < f.Values(); j++ {
-		var x int32
-		if err := binary.Read(rr, binary.LittleEndian, &x); err != nil {
-			return err
-		}
-		s := make([]byte, x)
-		if _, err := rr.Read(s); err != nil {
-			return err
-		}
-
-		f.vals = append(f.vals, string(s))
-	}
-	return nil
-}
-
-func (f *StringOptionalField) Levels() ([]uint8, []uint8) {
-	return f.Defs, f.Reps
-}
-
-type int64stats struct {
-	min int64
-	max int64
-}
-
-func newInt64stats() *int64stats {
-	return &int64stats{
-		min: int64(math.MaxInt64),
-	}
-}
-
-func (i *int64stats) add(val int64) {
-	if val < i.min {
-		i.min = val
-	}
-	if val > i.max {
-		i.max = val
-	}
-}
-
-func (f *int64stats) bytes(v int64) []byte {
-	bs := make([]byte, 8)
-	binary.LittleEndian.PutUint64(bs, uint64(v))
-	return bs
-}
-
-func (f *int64stats) NullCount() *int64 {
-	return nil
-}
-
-func (f *int64stats) DistinctCount() *int64 {
-	return nil
-}
-
-func (f *int64stats) Min() []byte {
-	return f.bytes(f.min)
-}
-
-func (f *int64stats) Max() []byte {
-	return f.bytes(f.max)
-}
-
-type int64optionalStats struct {
-	min     int64
-	max     int64
-	nils    int64
-	nonNils int64
-	maxDef  uint8
-}
-
-func newint64optionalStats(d uint8) *int64optionalStats {
-	return &int64optionalStats{
-		min:    int64(math.MaxInt64),
-		maxDef: d,
-	}
-}
-
-func (f *int64optionalStats) add(vals []int64, defs []uint8) {
-	var i int
-	for _, def := range defs {
-		if def < f.maxDef {
-			f.nils++
-		} else {
-			val := vals[i]
-			i++
-
-			f.nonNils++
-			if val < f.min {
-				f.min = val
-			}
-			if val > f.max {
-				f.max = val
-			}
-		}
-	}
-}
-
-func (f *int64optionalStats) bytes(v int64) []byte {
-	bs := make([]byte, 8)
-	binary.LittleEndian.PutUint64(bs, uint64(v))
-	return bs
-}
-
-func (f *int64optionalStats) NullCount() *int64 {
-	return &f.nils
-}
-
-func (f *int64optionalStats) DistinctCount() *int64 {
-	return nil
-}
-
-func (f *int64optionalStats) Min() []byte {
-	if f.nonNils == 0 {
-		return nil
-	}
-	return f.bytes(f.min)
-}
-
-func (f *int64optionalStats) Max() []byte {
-	if f.nonNils == 0 {
-		return nil
-	}
-	return f.bytes(f.max)
-}
-
-const nilOptString = "__#NIL#__"
-
-type stringOptionalStats struct {
-	min    string
-	max    string
-	nils   int64
-	maxDef uint8
-}
-
-func newStringOptionalStats(d uint8) *stringOptionalStats {
-	return &stringOptionalStats{
-		min:    nilOptString,
-		max:    nilOptString,
-		maxDef: d,
-	}
-}
-
-func (s *stringOptionalStats) add(vals []string, defs []uint8) {
-	var i int
-	for _, def := range defs {
-		if def < s.maxDef {
-			s.nils++
-		} else {
-			val := vals[i]
-			if s.min == nilOptString {
-				s.min = val
-			} else {
-				if val < s.min {
-					s.min = val
-				}
-			}
-			if s.max == nilOptString {
-				s.max = val
-			} else {
-				if val > s.max {
-					s.max = val
-				}
-			}
-			i++
-		}
-	}
-}
-
-func (s *stringOptionalStats) NullCount() *int64 {
-	return &s.nils
-}
-
-func (s *stringOptionalStats) DistinctCount() *int64 {
-	return nil
-}
-
-func (s *stringOptionalStats) Min() []byte {
-	if s.min == nilOptString {
-		return nil
-	}
-	return []byte(s.min)
-}
-
-func (s *stringOptionalStats) Max() []byte {
-	if s.max == nilOptString {
-		return nil
-	}
-	return []byte(s.max)
-}
-
-func pint32(i int32) *int32       { return &i }
-func puint32(i uint32) *uint32    { return &i }
-func pint64(i int64) *int64       { return &i }
-func puint64(i uint64) *uint64    { return &i }
-func pbool(b bool) *bool          { return &b }
-func pstring(s string) *string    { return &s }
-func pfloat32(f float32) *float32 { return &f }
-func pfloat64(f float64) *float64 { return &f }
-
-// keeps track of the indices of repeated fields
-// that have already been handled by a previous field
-type indices []int
-
-func (i indices) rep(rep uint8) {
-	if rep > 0 {
-		r := int(rep) - 1
-		i[r] = i[r] + 1
-		for j := int(rep); j < len(i); j++ {
-			i[j] = 0
-		}
-	}
-}
-
-func maxDef(types []int) uint8 {
-	var out uint8
-	for _, typ := range types {
-		if typ > 0 {
-			out++
-		}
-	}
-	return out
-}
-
-func Int32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-}
-
-func Uint32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_32
-	se.ConvertedType = &ct
-}
-
-func Int64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-}
-
-func Uint64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_64
-	se.ConvertedType = &ct
-}
-
-func Float32Type(se *sch.SchemaElement) {
-	t := sch.Type_FLOAT
-	se.Type = &t
-}
-
-func Float64Type(se *sch.SchemaElement) {
-	t := sch.Type_DOUBLE
-	se.Type = &t
-}
-
-func BoolType(se *sch.SchemaElement) {
-	t := sch.Type_BOOLEAN
-	se.Type = &t
-}
-
-func StringType(se *sch.SchemaElement) {
-	t := sch.Type_BYTE_ARRAY
-	se.Type = &t
-}
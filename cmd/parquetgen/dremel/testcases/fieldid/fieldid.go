@@ -0,0 +1,10 @@
+package fieldid
+
+//go:generate parquetgen -input fieldid.go -type Member -package fieldid -output generated.go
+
+type Member struct {
+	ID        int32  `parquet:"id"`
+	Happiness int64  `parquet:"happiness,id=2"`
+	Sadness   *int64 `parquet:"sadness,id=3"`
+	Sleepy    bool
+}
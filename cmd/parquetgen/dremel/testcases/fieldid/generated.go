@@ -0,0 +1,98 @@
+// Code generated by github.com/parsyl/parquet. DO NOT EDIT.
+package fieldid
+
+import (
+	"io"
+
+	"github.com/parsyl/parquet"
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// ParquetWriter writes Member records to a parquet file
+type ParquetWriter = parquet.Writer[Member]
+
+// ParquetReader reads Member records from a parquet file
+type ParquetReader = parquet.Reader[Member]
+
+// NewParquetWriter creates a writer for Member records
+func NewParquetWriter(w io.Writer, opts ...parquet.WriterOption) (*ParquetWriter, error) {
+	return parquet.NewCodecWriter[Member](w, MemberCodec{}, opts...)
+}
+
+// OpenForAppend creates a writer that adds row groups to the parquet file
+// in rws, which must have the schema of Member (see parquet.OpenForAppend).
+func OpenForAppend(rws io.ReadWriteSeeker, opts ...parquet.WriterOption) (*ParquetWriter, error) {
+	return parquet.OpenCodecForAppend[Member](rws, MemberCodec{}, opts...)
+}
+
+// NewParquetReader creates a reader for Member records
+func NewParquetReader(r io.ReadSeeker, opts ...parquet.ReaderOption) (*ParquetReader, error) {
+	return parquet.NewCodecReader[Member](r, MemberCodec{}, opts...)
+}
+
+// NewParquetReaderAt creates a reader for the parquet file of the given size.
+// The file is only read with ReadAt so several readers (for example, each one
+// reading a different row group) can share r from different goroutines.
+func NewParquetReaderAt(r io.ReaderAt, size int64, opts ...parquet.ReaderOption) (*ParquetReader, error) {
+	return parquet.NewCodecReaderAt[Member](r, size, MemberCodec{}, opts...)
+}
+
+// MemberCodec is the parquet.Codec of Member
+type MemberCodec struct{}
+
+// Fields creates the columns of Member
+func (MemberCodec) Fields(c sch.CompressionCodec) []parquet.Column[Member] {
+	return []parquet.Column[Member]{
+		parquet.NewRequiredColumn(readID, writeID, []string{"id"}, parquet.RequiredFieldCompression(c)),
+		parquet.NewRequiredColumn(readHappiness, writeHappiness, []string{"happiness"}, parquet.RequiredFieldCompression(c), parquet.RequiredFieldID(2)),
+		parquet.NewOptionalColumn(readSadness, writeSadness, []string{"sadness"}, []int{1}, parquet.OptionalFieldCompression(c), parquet.OptionalFieldID(3)),
+		parquet.NewRequiredColumn(readSleepy, writeSleepy, []string{"Sleepy"}, parquet.RequiredFieldCompression(c)),
+	}
+}
+
+func readID(x Member) int32 {
+	return x.ID
+}
+
+func writeID(x *Member, vals []int32) {
+	x.ID = vals[0]
+}
+
+func readHappiness(x Member) int64 {
+	return x.Happiness
+}
+
+func writeHappiness(x *Member, vals []int64) {
+	x.Happiness = vals[0]
+}
+
+func readSadness(x Member, vals []int64, defs, reps []uint8) ([]int64, []uint8, []uint8) {
+	switch {
+	case x.Sadness == nil:
+		defs = append(defs, 0)
+		return vals, defs, reps
+	default:
+		vals = append(vals, *x.Sadness)
+		defs = append(defs, 1)
+		return vals, defs, reps
+	}
+}
+
+func writeSadness(x *Member, vals []int64, defs, reps []uint8) (int, int) {
+	def := defs[0]
+	switch def {
+	case 1:
+		x.Sadness = parquet.Ptr(vals[0])
+		return 1, 1
+	}
+
+	return 0, 1
+}
+
+func readSleepy(x Member) bool {
+	return x.Sleepy
+}
+
+func writeSleepy(x *Member, vals []bool) {
+	x.Sleepy = vals[0]
+}
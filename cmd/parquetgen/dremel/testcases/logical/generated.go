@@ -0,0 +1,155 @@
+// Code generated by github.com/parsyl/parquet. DO NOT EDIT.
+package logical
+
+import (
+	"io"
+
+	"github.com/parsyl/parquet"
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// ParquetWriter writes Invoice records to a parquet file
+type ParquetWriter = parquet.Writer[Invoice]
+
+// ParquetReader reads Invoice records from a parquet file
+type ParquetReader = parquet.Reader[Invoice]
+
+// NewParquetWriter creates a writer for Invoice records
+func NewParquetWriter(w io.Writer, opts ...parquet.WriterOption) (*ParquetWriter, error) {
+	return parquet.NewCodecWriter[Invoice](w, InvoiceCodec{}, opts...)
+}
+
+// OpenForAppend creates a writer that adds row groups to the parquet file
+// in rws, which must have the schema of Invoice (see parquet.OpenForAppend).
+func OpenForAppend(rws io.ReadWriteSeeker, opts ...parquet.WriterOption) (*ParquetWriter, error) {
+	return parquet.OpenCodecForAppend[Invoice](rws, InvoiceCodec{}, opts...)
+}
+
+// NewParquetReader creates a reader for Invoice records
+func NewParquetReader(r io.ReadSeeker, opts ...parquet.ReaderOption) (*ParquetReader, error) {
+	return parquet.NewCodecReader[Invoice](r, InvoiceCodec{}, opts...)
+}
+
+// NewParquetReaderAt creates a reader for the parquet file of the given size.
+// The file is only read with ReadAt so several readers (for example, each one
+// reading a different row group) can share r from different goroutines.
+func NewParquetReaderAt(r io.ReaderAt, size int64, opts ...parquet.ReaderOption) (*ParquetReader, error) {
+	return parquet.NewCodecReaderAt[Invoice](r, size, InvoiceCodec{}, opts...)
+}
+
+// InvoiceCodec is the parquet.Codec of Invoice
+type InvoiceCodec struct{}
+
+// Fields creates the columns of Invoice
+func (InvoiceCodec) Fields(c sch.CompressionCodec) []parquet.Column[Invoice] {
+	return []parquet.Column[Invoice]{
+		parquet.NewRequiredColumn(readId, writeId, []string{"id"}, parquet.RequiredFieldCompression(c), parquet.RequiredFieldID(1)),
+		parquet.NewRequiredColumn(readTotal, writeTotal, []string{"total"}, parquet.RequiredFieldCompression(c), parquet.RequiredFieldAnnotation(0, &sch.LogicalType{DECIMAL: &sch.DecimalType{Precision: 9, Scale: 2}}, parquet.Ptr(sch.ConvertedType_DECIMAL))),
+		parquet.NewOptionalColumn(readDue, writeDue, []string{"due"}, []int{1}, parquet.OptionalFieldCompression(c), parquet.OptionalFieldAnnotation(0, &sch.LogicalType{DATE: &sch.DateType{}}, parquet.Ptr(sch.ConvertedType_DATE))),
+		parquet.NewRequiredColumn(readCustomer, writeCustomer, []string{"customer"}, parquet.RequiredFieldCompression(c), parquet.RequiredFieldAnnotation(0, &sch.LogicalType{STRING: &sch.StringType{}}, parquet.Ptr(sch.ConvertedType_UTF8))),
+		parquet.NewOptionalColumn(readLinesListElement, writeLinesListElement, []string{"lines", "list", "element"}, []int{1, 2, 0}, parquet.OptionalFieldCompression(c), parquet.OptionalFieldAnnotation(0, &sch.LogicalType{LIST: &sch.ListType{}}, parquet.Ptr(sch.ConvertedType_LIST)), parquet.OptionalFieldAnnotation(2, &sch.LogicalType{STRING: &sch.StringType{}}, parquet.Ptr(sch.ConvertedType_UTF8))),
+	}
+}
+
+func readId(x Invoice) int64 {
+	return x.Id
+}
+
+func writeId(x *Invoice, vals []int64) {
+	x.Id = vals[0]
+}
+
+func readTotal(x Invoice) int32 {
+	return x.Total
+}
+
+func writeTotal(x *Invoice, vals []int32) {
+	x.Total = vals[0]
+}
+
+func readDue(x Invoice, vals []int32, defs, reps []uint8) ([]int32, []uint8, []uint8) {
+	switch {
+	case x.Due == nil:
+		defs = append(defs, 0)
+		return vals, defs, reps
+	default:
+		vals = append(vals, *x.Due)
+		defs = append(defs, 1)
+		return vals, defs, reps
+	}
+}
+
+func writeDue(x *Invoice, vals []int32, defs, reps []uint8) (int, int) {
+	def := defs[0]
+	switch def {
+	case 1:
+		x.Due = parquet.Ptr(vals[0])
+		return 1, 1
+	}
+
+	return 0, 1
+}
+
+func readCustomer(x Invoice) string {
+	return x.Customer
+}
+
+func writeCustomer(x *Invoice, vals []string) {
+	x.Customer = vals[0]
+}
+
+func readLinesListElement(x Invoice, vals []string, defs, reps []uint8) ([]string, []uint8, []uint8) {
+	var lastRep uint8
+
+	if x.Lines == nil {
+		defs = append(defs, 0)
+		reps = append(reps, lastRep)
+	} else {
+		if len(x.Lines.List) == 0 {
+			defs = append(defs, 1)
+			reps = append(reps, lastRep)
+		} else {
+			for i0, x0 := range x.Lines.List {
+				if i0 >= 1 {
+					lastRep = 1
+				}
+				defs = append(defs, 2)
+				reps = append(reps, lastRep)
+				vals = append(vals, x0.Element)
+			}
+		}
+	}
+
+	return vals, defs, reps
+}
+
+func writeLinesListElement(x *Invoice, vals []string, defs, reps []uint8) (int, int) {
+	var nVals, nLevels int
+	ind := make(parquet.Indices, 1)
+
+	for i := range defs {
+		def := defs[i]
+		rep := reps[i]
+		if i > 0 && rep == 0 {
+			break
+		}
+
+		nLevels++
+		ind.Rep(rep)
+
+		switch def {
+		case 1:
+			x.Lines = &Lines{}
+		case 2:
+			switch rep {
+			case 0:
+				x.Lines = &Lines{List: []List{{Element: vals[nVals]}}}
+			case 1:
+				x.Lines.List = append(x.Lines.List, List{Element: vals[nVals]})
+			}
+			nVals++
+		}
+	}
+
+	return nVals, nLevels
+}
@@ -0,0 +1,19 @@
+package logical
+
+// This code is generated by github.com/parsyl/parquet.
+
+type Invoice struct {
+	Id       int64  `parquet:"id,id=1"`
+	Total    int32  `parquet:"total,logical=DECIMAL(9,2)"`
+	Due      *int32 `parquet:"due,logical=DATE"`
+	Customer string `parquet:"customer,logical=STRING"`
+	Lines    *Lines `parquet:"lines,logical=LIST"`
+}
+
+type Lines struct {
+	List []List `parquet:"list"`
+}
+
+type List struct {
+	Element string `parquet:"element,logical=STRING"`
+}
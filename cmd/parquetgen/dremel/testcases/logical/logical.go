@@ -0,0 +1,4 @@
+// Package logical is generated from a schema with logical types.
+package logical
+
+//go:generate parquetgen -schema invoice.schema -type Invoice -package logical -struct-output invoice.go -output generated.go
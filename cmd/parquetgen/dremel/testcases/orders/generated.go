@@ -0,0 +1,244 @@
+// Code generated by github.com/parsyl/parquet. DO NOT EDIT.
+package orders
+
+import (
+	"io"
+
+	"github.com/parsyl/parquet"
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// CustomerParquetWriter writes Customer records to a parquet file
+type CustomerParquetWriter = parquet.Writer[Customer]
+
+// CustomerParquetReader reads Customer records from a parquet file
+type CustomerParquetReader = parquet.Reader[Customer]
+
+// NewCustomerParquetWriter creates a writer for Customer records
+func NewCustomerParquetWriter(w io.Writer, opts ...parquet.WriterOption) (*CustomerParquetWriter, error) {
+	return parquet.NewCodecWriter[Customer](w, CustomerCodec{}, opts...)
+}
+
+// OpenCustomerForAppend creates a writer that adds row groups to the parquet file
+// in rws, which must have the schema of Customer (see parquet.OpenForAppend).
+func OpenCustomerForAppend(rws io.ReadWriteSeeker, opts ...parquet.WriterOption) (*CustomerParquetWriter, error) {
+	return parquet.OpenCodecForAppend[Customer](rws, CustomerCodec{}, opts...)
+}
+
+// NewCustomerParquetReader creates a reader for Customer records
+func NewCustomerParquetReader(r io.ReadSeeker, opts ...parquet.ReaderOption) (*CustomerParquetReader, error) {
+	return parquet.NewCodecReader[Customer](r, CustomerCodec{}, opts...)
+}
+
+// NewCustomerParquetReaderAt creates a reader for the parquet file of the given size.
+// The file is only read with ReadAt so several readers (for example, each one
+// reading a different row group) can share r from different goroutines.
+func NewCustomerParquetReaderAt(r io.ReaderAt, size int64, opts ...parquet.ReaderOption) (*CustomerParquetReader, error) {
+	return parquet.NewCodecReaderAt[Customer](r, size, CustomerCodec{}, opts...)
+}
+
+// CustomerCodec is the parquet.Codec of Customer
+type CustomerCodec struct{}
+
+// Fields creates the columns of Customer
+func (CustomerCodec) Fields(c sch.CompressionCodec) []parquet.Column[Customer] {
+	return []parquet.Column[Customer]{
+		parquet.NewRequiredColumn(readCustomerID, writeCustomerID, []string{"id"}, parquet.RequiredFieldCompression(c)),
+		parquet.NewRequiredColumn(readCustomerName, writeCustomerName, []string{"name"}, parquet.RequiredFieldCompression(c)),
+		parquet.NewOptionalColumn(readCustomerEmail, writeCustomerEmail, []string{"email"}, []int{1}, parquet.OptionalFieldCompression(c)),
+	}
+}
+
+func readCustomerID(x Customer) int64 {
+	return x.ID
+}
+
+func writeCustomerID(x *Customer, vals []int64) {
+	x.ID = vals[0]
+}
+
+func readCustomerName(x Customer) string {
+	return x.Name
+}
+
+func writeCustomerName(x *Customer, vals []string) {
+	x.Name = vals[0]
+}
+
+func readCustomerEmail(x Customer, vals []string, defs, reps []uint8) ([]string, []uint8, []uint8) {
+	switch {
+	case x.Email == nil:
+		defs = append(defs, 0)
+		return vals, defs, reps
+	default:
+		vals = append(vals, *x.Email)
+		defs = append(defs, 1)
+		return vals, defs, reps
+	}
+}
+
+func writeCustomerEmail(x *Customer, vals []string, defs, reps []uint8) (int, int) {
+	def := defs[0]
+	switch def {
+	case 1:
+		x.Email = parquet.Ptr(vals[0])
+		return 1, 1
+	}
+
+	return 0, 1
+}
+
+// OrderParquetWriter writes Order records to a parquet file
+type OrderParquetWriter = parquet.Writer[Order]
+
+// OrderParquetReader reads Order records from a parquet file
+type OrderParquetReader = parquet.Reader[Order]
+
+// NewOrderParquetWriter creates a writer for Order records
+func NewOrderParquetWriter(w io.Writer, opts ...parquet.WriterOption) (*OrderParquetWriter, error) {
+	return parquet.NewCodecWriter[Order](w, OrderCodec{}, opts...)
+}
+
+// OpenOrderForAppend creates a writer that adds row groups to the parquet file
+// in rws, which must have the schema of Order (see parquet.OpenForAppend).
+func OpenOrderForAppend(rws io.ReadWriteSeeker, opts ...parquet.WriterOption) (*OrderParquetWriter, error) {
+	return parquet.OpenCodecForAppend[Order](rws, OrderCodec{}, opts...)
+}
+
+// NewOrderParquetReader creates a reader for Order records
+func NewOrderParquetReader(r io.ReadSeeker, opts ...parquet.ReaderOption) (*OrderParquetReader, error) {
+	return parquet.NewCodecReader[Order](r, OrderCodec{}, opts...)
+}
+
+// NewOrderParquetReaderAt creates a reader for the parquet file of the given size.
+// The file is only read with ReadAt so several readers (for example, each one
+// reading a different row group) can share r from different goroutines.
+func NewOrderParquetReaderAt(r io.ReaderAt, size int64, opts ...parquet.ReaderOption) (*OrderParquetReader, error) {
+	return parquet.NewCodecReaderAt[Order](r, size, OrderCodec{}, opts...)
+}
+
+// OrderCodec is the parquet.Codec of Order
+type OrderCodec struct{}
+
+// Fields creates the columns of Order
+func (OrderCodec) Fields(c sch.CompressionCodec) []parquet.Column[Order] {
+	return []parquet.Column[Order]{
+		parquet.NewRequiredColumn(readOrderID, writeOrderID, []string{"id"}, parquet.RequiredFieldCompression(c)),
+		parquet.NewRequiredColumn(readOrderCustomerID, writeOrderCustomerID, []string{"customer_id"}, parquet.RequiredFieldCompression(c)),
+		parquet.NewOptionalColumn(readOrderItemsName, writeOrderItemsName, []string{"items", "name"}, []int{2, 0}, parquet.OptionalFieldCompression(c)),
+		parquet.NewOptionalColumn(readOrderItemsQuantity, writeOrderItemsQuantity, []string{"items", "quantity"}, []int{2, 0}, parquet.OptionalFieldCompression(c)),
+		parquet.NewRequiredColumn(readOrderTotal, writeOrderTotal, []string{"total"}, parquet.RequiredFieldCompression(c)),
+	}
+}
+
+func readOrderID(x Order) int64 {
+	return x.ID
+}
+
+func writeOrderID(x *Order, vals []int64) {
+	x.ID = vals[0]
+}
+
+func readOrderCustomerID(x Order) int64 {
+	return x.CustomerID
+}
+
+func writeOrderCustomerID(x *Order, vals []int64) {
+	x.CustomerID = vals[0]
+}
+
+func readOrderItemsName(x Order, vals []string, defs, reps []uint8) ([]string, []uint8, []uint8) {
+	var lastRep uint8
+
+	if len(x.Items) == 0 {
+		defs = append(defs, 0)
+		reps = append(reps, lastRep)
+	} else {
+		for i0, x0 := range x.Items {
+			if i0 >= 1 {
+				lastRep = 1
+			}
+			defs = append(defs, 1)
+			reps = append(reps, lastRep)
+			vals = append(vals, x0.Name)
+		}
+	}
+
+	return vals, defs, reps
+}
+
+func writeOrderItemsName(x *Order, vals []string, defs, reps []uint8) (int, int) {
+	var nVals, nLevels int
+	ind := make(parquet.Indices, 1)
+
+	for i := range defs {
+		def := defs[i]
+		rep := reps[i]
+		if i > 0 && rep == 0 {
+			break
+		}
+
+		nLevels++
+		ind.Rep(rep)
+
+		switch def {
+		case 1:
+			x.Items = append(x.Items, Item{Name: vals[nVals]})
+			nVals++
+		}
+	}
+
+	return nVals, nLevels
+}
+
+func readOrderItemsQuantity(x Order, vals []int32, defs, reps []uint8) ([]int32, []uint8, []uint8) {
+	var lastRep uint8
+
+	if len(x.Items) == 0 {
+		defs = append(defs, 0)
+		reps = append(reps, lastRep)
+	} else {
+		for i0, x0 := range x.Items {
+			if i0 >= 1 {
+				lastRep = 1
+			}
+			defs = append(defs, 1)
+			reps = append(reps, lastRep)
+			vals = append(vals, x0.Quantity)
+		}
+	}
+
+	return vals, defs, reps
+}
+
+func writeOrderItemsQuantity(x *Order, vals []int32, defs, reps []uint8) (int, int) {
+	var nVals, nLevels int
+	ind := make(parquet.Indices, 1)
+
+	for i := range defs {
+		def := defs[i]
+		rep := reps[i]
+		if i > 0 && rep == 0 {
+			break
+		}
+
+		nLevels++
+		ind.Rep(rep)
+
+		switch def {
+		case 1:
+			x.Items[ind[0]].Quantity = vals[nVals]
+			nVals++
+		}
+	}
+
+	return nVals, nLevels
+}
+
+func readOrderTotal(x Order) float64 {
+	return x.Total
+}
+
+func writeOrderTotal(x *Order, vals []float64) {
+	x.Total = vals[0]
+}
@@ -0,0 +1,21 @@
+package orders
+
+//go:generate parquetgen -input orders.go -type Customer,Order -package orders -output generated.go
+
+type Customer struct {
+	ID    int64   `parquet:"id"`
+	Name  string  `parquet:"name"`
+	Email *string `parquet:"email"`
+}
+
+type Item struct {
+	Name     string `parquet:"name"`
+	Quantity int32  `parquet:"quantity"`
+}
+
+type Order struct {
+	ID         int64   `parquet:"id"`
+	CustomerID int64   `parquet:"customer_id"`
+	Items      []Item  `parquet:"items"`
+	Total      float64 `parquet:"total"`
+}
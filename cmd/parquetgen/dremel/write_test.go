@@ -35,7 +35,7 @@ func TestWrite(t *testing.T) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ID = pint32(vals[0])
+		x.ID = parquet.Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -68,7 +68,7 @@ func TestWrite(t *testing.T) {
 	case 1:
 		x.Hobby = &Hobby{}
 	case 2:
-		x.Hobby = &Hobby{Difficulty: pint32(vals[0])}
+		x.Hobby = &Hobby{Difficulty: parquet.Ptr(vals[0])}
 		return 1, 1
 	}
 
@@ -87,7 +87,7 @@ func TestWrite(t *testing.T) {
 	def := defs[0]
 	switch def {
 	case 2:
-		x.Hobby.Difficulty = pint32(vals[0])
+		x.Hobby.Difficulty = parquet.Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -123,7 +123,7 @@ func TestWrite(t *testing.T) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.Hobby.Name = pstring(vals[0])
+		x.Hobby.Name = parquet.Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -145,7 +145,7 @@ func TestWrite(t *testing.T) {
 	case 1:
 		x.Friend = &Entity{}
 	case 2:
-		x.Friend = &Entity{Hobby: Item{Name: pstring(vals[0])}}
+		x.Friend = &Entity{Hobby: Item{Name: parquet.Ptr(vals[0])}}
 		return 1, 1
 	}
 
@@ -166,7 +166,7 @@ func TestWrite(t *testing.T) {
 	def := defs[0]
 	switch def {
 	case 2:
-		x.Friend.Hobby.Name = pstring(vals[0])
+		x.Friend.Hobby.Name = parquet.Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -212,7 +212,7 @@ func TestWrite(t *testing.T) {
 	case 2:
 		x.Friend = &Entity{Hobby: &Item{}}
 	case 3:
-		x.Friend = &Entity{Hobby: &Item{Name: pstring(vals[0])}}
+		x.Friend = &Entity{Hobby: &Item{Name: parquet.Ptr(vals[0])}}
 		return 1, 1
 	}
 
@@ -235,7 +235,7 @@ func TestWrite(t *testing.T) {
 	case 2:
 		x.Friend.Hobby = &Item{}
 	case 3:
-		x.Friend.Hobby = &Item{Name: pstring(vals[0])}
+		x.Friend.Hobby = &Item{Name: parquet.Ptr(vals[0])}
 		return 1, 1
 	}
 
@@ -263,7 +263,7 @@ func TestWrite(t *testing.T) {
 	case 3:
 		x.Friend = &Entity{Hobby: &Item{Name: &Name{}}}
 	case 4:
-		x.Friend = &Entity{Hobby: &Item{Name: &Name{First: pstring(vals[0])}}}
+		x.Friend = &Entity{Hobby: &Item{Name: &Name{First: parquet.Ptr(vals[0])}}}
 		return 1, 1
 	}
 
@@ -290,7 +290,7 @@ func TestWrite(t *testing.T) {
 	case 3:
 		x.Friend.Hobby = &Item{Name: &Name{}}
 	case 4:
-		x.Friend.Hobby = &Item{Name: &Name{First: pstring(vals[0])}}
+		x.Friend.Hobby = &Item{Name: &Name{First: parquet.Ptr(vals[0])}}
 		return 1, 1
 	}
 
@@ -316,7 +316,7 @@ func TestWrite(t *testing.T) {
 	case 2:
 		x.Friend.Hobby = &Item{Name: &Name{}}
 	case 3:
-		x.Friend.Hobby = &Item{Name: &Name{First: pstring(vals[0])}}
+		x.Friend.Hobby = &Item{Name: &Name{First: parquet.Ptr(vals[0])}}
 		return 1, 1
 	}
 
@@ -343,7 +343,7 @@ func TestWrite(t *testing.T) {
 	case 2:
 		x.Friend.Hobby = &Item{Name: &Name{}}
 	case 3:
-		x.Friend.Hobby = &Item{Name: &Name{First: pstring(vals[0])}}
+		x.Friend.Hobby = &Item{Name: &Name{First: parquet.Ptr(vals[0])}}
 		return 1, 1
 	}
 
@@ -415,7 +415,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinkBackward(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -425,7 +425,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -455,7 +455,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinkForward(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -465,7 +465,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -489,7 +489,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeNamesLanguagesCode(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 2)
+	ind := make(parquet.Indices, 2)
 
 	for i := range defs {
 		def := defs[i]
@@ -499,7 +499,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -531,7 +531,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeNamesLanguagesCountry(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 2)
+	ind := make(parquet.Indices, 2)
 
 	for i := range defs {
 		def := defs[i]
@@ -541,11 +541,11 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 3:
-			x.Names[ind[0]].Languages[ind[1]].Country = pstring(vals[nVals])
+			x.Names[ind[0]].Languages[ind[1]].Country = parquet.Ptr(vals[nVals])
 			nVals++
 		}
 	}
@@ -563,7 +563,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeFriendsID(x *Person, vals []int32, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -573,7 +573,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -593,7 +593,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLuckyNumbers(x *Document, vals []int64, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -603,7 +603,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -626,7 +626,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinkForward(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -636,7 +636,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -661,7 +661,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeHobbySkillsDifficulty(x *Person, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -671,7 +671,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -700,7 +700,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinksForwardCountries(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 3)
+	ind := make(parquet.Indices, 3)
 
 	for i := range defs {
 		def := defs[i]
@@ -710,7 +710,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 3:
@@ -738,7 +738,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinksForwardCodes(x *Doc, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 3)
+	ind := make(parquet.Indices, 3)
 
 	for i := range defs {
 		def := defs[i]
@@ -748,7 +748,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -764,6 +764,96 @@ func TestWrite(t *testing.T) {
 		}
 	}
 
+	return nVals, nLevels
+}`,
+		},
+		{
+			name: "optional list with a required element",
+			field: fields.Field{
+				Name: "Tags", Type: "Tags", RepetitionType: fields.Optional, Children: []fields.Field{
+					{Name: "List", Type: "Item", RepetitionType: fields.Repeated, Children: []fields.Field{
+						{Name: "Element", Type: "string", RepetitionType: fields.Required},
+					}},
+				},
+			},
+			result: `func writeTagsListElement(x *Person, vals []string, defs, reps []uint8) (int, int) {
+	var nVals, nLevels int
+	ind := make(parquet.Indices, 1)
+
+	for i := range defs {
+		def := defs[i]
+		rep := reps[i]
+		if i > 0 && rep == 0 {
+			break
+		}
+
+		nLevels++
+		ind.Rep(rep)
+
+		switch def {
+		case 1:
+			x.Tags = &Tags{}
+		case 2:
+			switch rep {
+			case 0:
+				x.Tags = &Tags{List: []Item{{Element: vals[nVals]}}}
+			case 1:
+				x.Tags.List = append(x.Tags.List, Item{Element: vals[nVals]})
+			}
+			nVals++
+		}
+	}
+
+	return nVals, nLevels
+}`,
+		},
+		{
+			name: "optional list in a repeated group handled by a previous field",
+			field: fields.Field{
+				Name: "Friends", Type: "Friend", RepetitionType: fields.Repeated, Children: []fields.Field{
+					{Name: "ID", Type: "int64", RepetitionType: fields.Required},
+					{Name: "Tags", Type: "Tags", RepetitionType: fields.Optional, Children: []fields.Field{
+						{Name: "List", Type: "Item", RepetitionType: fields.Repeated, Children: []fields.Field{
+							{Name: "Element", Type: "string", RepetitionType: fields.Optional},
+						}},
+					}},
+				},
+			},
+			result: `func writeFriendsTagsListElement(x *Person, vals []string, defs, reps []uint8) (int, int) {
+	var nVals, nLevels int
+	ind := make(parquet.Indices, 2)
+
+	for i := range defs {
+		def := defs[i]
+		rep := reps[i]
+		if i > 0 && rep == 0 {
+			break
+		}
+
+		nLevels++
+		ind.Rep(rep)
+
+		switch def {
+		case 2:
+			x.Friends[ind[0]].Tags = &Tags{}
+		case 3:
+			switch rep {
+			case 0, 1:
+				x.Friends[ind[0]].Tags = &Tags{List: []Item{{}}}
+			case 2:
+				x.Friends[ind[0]].Tags.List = append(x.Friends[ind[0]].Tags.List, Item{})
+			}
+		case 4:
+			switch rep {
+			case 0, 1:
+				x.Friends[ind[0]].Tags = &Tags{List: []Item{{parquet.Ptr(vals[nVals])}}}
+			case 2:
+				x.Friends[ind[0]].Tags.List = append(x.Friends[ind[0]].Tags.List, Item{parquet.Ptr(vals[nVals])})
+			}
+			nVals++
+		}
+	}
+
 	return nVals, nLevels
 }`,
 		},
@@ -8,15 +8,23 @@ import (
 // Field holds metadata that is required by parquetgen in order
 // to generate code.
 type Field struct {
-	Type           string
-	Name           string
-	ColumnName     string
+	Type       string
+	Name       string
+	ColumnName string
+	ID         int32
+	// Annotation is the logical (or converted) type of the column or
+	// group in the syntax of a parquet schema, for example DECIMAL(9,2)
+	Annotation     string
 	RepetitionType RepetitionType
 	Parent         *Field
 	Children       []Field
 	Embedded       bool
 	NthChild       int
 	Defined        bool
+	// Prefix is only set on the root field.  It is added to the names
+	// of the read and write funcs of the columns (see FuncName) so that
+	// the code for more than one struct can be generated into a package.
+	Prefix string
 }
 
 type input struct {
@@ -94,6 +102,16 @@ func (f Field) FieldNames() []string {
 	return out
 }
 
+// FuncName is the name of the read and write funcs of the column
+// (read<FuncName> and write<FuncName>).
+func (f Field) FuncName() string {
+	root := f
+	for root.Parent != nil {
+		root = *root.Parent
+	}
+	return root.Prefix + strings.Join(f.FieldNames(), "")
+}
+
 func (f Field) FieldTypes() []string {
 	var out []string
 	for _, fld := range Reverse(f.Chain()) {
@@ -296,11 +314,11 @@ func (f Field) Required() bool {
 }
 
 func (f Field) leftComplete(fld Field, i, def, rep, maxDef, maxRep, defs, reps int) bool {
-	if fld.RepetitionType == Optional && rep == 0 && !fld.Defined {
+	if fld.RepetitionType == Optional && rep <= reps && !fld.Defined {
 		return true
 	}
 
-	if fld.RepetitionType == Repeated && rep > 0 && reps == rep && f.NthChild == 0 {
+	if fld.RepetitionType == Repeated && rep > 0 && reps == rep && f.NthChild == 0 && !fld.Defined {
 		return true
 	}
 
@@ -400,15 +418,15 @@ func (f Field) Init(def, rep int) string {
 		case Optional:
 			if fld.Primitive() {
 				if f.NthChild == 0 && fld.Parent.Optional() && !fld.Parent.Repeated() {
-					right = fmt.Sprintf(right, fmt.Sprintf("%s: p%s(vals[0])%%s", fld.Name, fld.Type))
+					right = fmt.Sprintf(right, fmt.Sprintf("%s: parquet.Ptr(vals[0])%%s", fld.Name))
 				} else if fld.Parent.RepetitionType == Repeated {
-					right = fmt.Sprintf(right, fmt.Sprintf("p%s(vals[nVals])%%s", fld.Type))
+					right = fmt.Sprintf(right, "parquet.Ptr(vals[nVals])%s")
 				} else if fld.Parent.Repeated() && f.NthChild == 0 {
-					right = fmt.Sprintf(right, fmt.Sprintf("%s: p%s(vals[nVals])%%s", fld.Name, fld.Type))
+					right = fmt.Sprintf(right, fmt.Sprintf("%s: parquet.Ptr(vals[nVals])%%s", fld.Name))
 				} else if fld.Parent.Repeated() && f.NthChild > 0 {
-					right = fmt.Sprintf(right, fmt.Sprintf("p%s(vals[nVals])%%s", fld.Type))
+					right = fmt.Sprintf(right, "parquet.Ptr(vals[nVals])%s")
 				} else {
-					right = fmt.Sprintf(right, fmt.Sprintf("p%s(vals[0])%%s", fld.Type))
+					right = fmt.Sprintf(right, "parquet.Ptr(vals[0])%s")
 				}
 			} else {
 				if j == 0 {
@@ -427,7 +445,7 @@ func (f Field) Init(def, rep int) string {
 					right = fmt.Sprintf(right, fmt.Sprintf("[]%s{vals[nVals]}%%s", fld.Type))
 				}
 			} else {
-				if rep > 0 && reps == rep || (fld.MaxRepForDef(def) == rep && !strings.Contains(right, "append(")) {
+				if j == 0 && (rep > 0 && reps == rep || (fld.MaxRepForDef(def) == rep && !strings.Contains(right, "append("))) {
 					right = fmt.Sprintf(right, fmt.Sprintf("append(x%s, %s{%%s})", left, fld.Type))
 				} else if rep == 0 && j == 0 && !f.rightComplete(def, defs, maxDef) {
 					right = fmt.Sprintf(right, fmt.Sprintf("[]%s{{%%s}}", fld.Type))
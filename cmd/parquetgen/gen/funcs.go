@@ -5,35 +5,27 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/parsyl/parquet/cmd/parquetgen/cases"
+	"github.com/parsyl/parquet"
 	"github.com/parsyl/parquet/cmd/parquetgen/dremel"
 	"github.com/parsyl/parquet/cmd/parquetgen/fields"
+	sch "github.com/parsyl/parquet/schema"
 )
 
 var (
 	funcs = template.FuncMap{
-		"removeStar": func(s string) string {
-			return strings.Replace(strings.Replace(s, "*", "", 1), "[]", "", 1)
-		},
-		"camelCase": func(s string) string {
-			return cases.Camel(s)
-		},
-		"camelCaseRemoveStar": func(s string) string {
-			return cases.Camel(strings.Replace(strings.Replace(s, "*", "", 1), "[]", "", 1))
-		},
-		"dedupe": dedupe,
 		"compressionFunc": func(f fields.Field) string {
 			if strings.Contains(f.Category(), "Optional") {
-				return "optionalFieldCompression"
+				return "parquet.OptionalFieldCompression"
 			}
-			return "fieldCompression"
-		},
-		"funcName": func(f fields.Field) string {
-			return strings.Join(f.FieldNames(), "")
+			return "parquet.RequiredFieldCompression"
 		},
-		"join": func(names []string) string {
-			return strings.Join(names, ".")
+		"fieldIDFunc": func(f fields.Field) string {
+			if strings.Contains(f.Category(), "Optional") {
+				return "parquet.OptionalFieldID"
+			}
+			return "parquet.RequiredFieldID"
 		},
+		"annotations": annotations,
 		"joinTypes": func(t []fields.RepetitionType) string {
 			names := make([]string, len(t))
 			for i, ty := range t {
@@ -41,86 +33,82 @@ var (
 			}
 			return strings.Join(names, ", ")
 		},
-		"maxType": func(f fields.Field) string {
-			var out string
-			switch f.Type {
-			case "int32", "*int32":
-				out = "math.MaxInt32"
-			case "int64", "*int64":
-				out = "math.MaxInt64"
-			case "uint32", "*uint32":
-				out = "math.MaxUint32"
-			case "uint64", "*uint64":
-				out = "math.MaxUint64"
-			case "float32", "*float32":
-				out = "math.MaxFloat32"
-			case "float64", "*float64":
-				out = "math.MaxFloat64"
-			}
-			return out
-		},
-		"columnName":    func(f fields.Field) string { return strings.Join(f.ColumnNames(), ".") },
 		"writeFunc":     dremel.Write,
 		"readFunc":      dremel.Read,
-		"writeFuncName": func(f fields.Field) string { return fmt.Sprintf("write%s", strings.Join(f.FieldNames(), "")) },
-		"readFuncName":  func(f fields.Field) string { return fmt.Sprintf("read%s", strings.Join(f.FieldNames(), "")) },
-		"parquetType": func(f fields.Field) string {
-			if f.Optional() {
-				return "parquet.OptionalField"
-			}
-			return "parquet.RequiredField"
-		},
-		"byteSize": func(f fields.Field) string {
-			var out string
-			switch f.Type {
-			case "int32", "*int32", "uint32", "*uint32", "float32", "*float32":
-				out = "4"
-			case "int64", "*int64", "uint64", "*uint64", "float64", "*float64":
-				out = "8"
-			}
-			return out
-		},
-		// based on binary.Write
-		"putFunc": func(f fields.Field) string {
-			var out string
-			switch f.Type {
-			case "int32", "*int32", "uint32", "*uint32", "float32", "*float32":
-				out = "PutUint32"
-			case "int64", "*int64", "uint64", "*uint64", "float64", "*float64":
-				out = "PutUint64"
-			}
-			return out
-		},
-		// based on binary.Write
-		"uintFunc": func(f fields.Field) string {
-			var out string
-			switch f.Type {
-			case "int32":
-				out = "uint32(v)"
-			case "*int32":
-				out = "uint32(*v)"
-			case "uint32":
-				out = "v"
-			case "*uint32":
-				out = "*v"
-			case "float32":
-				out = "math.Float32bits(v)"
-			case "*float32":
-				out = "math.Float32bits(*v)"
-			case "int64":
-				out = "uint64(v)"
-			case "*int64":
-				out = "uint64(*v)"
-			case "uint64":
-				out = "v"
-			case "*uint64":
-				out = "*v"
-			case "float64":
-				out = "math.Float64bits(v)"
-			case "*float64":
-				out = "math.Float64bits(*v)"
-			}
-			return out
-		},
+		"writeFuncName": func(f fields.Field) string { return fmt.Sprintf("write%s", f.FuncName()) },
+		"readFuncName":  func(f fields.Field) string { return fmt.Sprintf("read%s", f.FuncName()) },
 	}
 )
+
+// annotations returns the options that set the logical and converted
+// types of the levels of f's path that have an annotation.
+func annotations(f fields.Field) (string, error) {
+	opt := "parquet.RequiredFieldAnnotation"
+	if strings.Contains(f.Category(), "Optional") {
+		opt = "parquet.OptionalFieldAnnotation"
+	}
+
+	var out string
+	for i, fld := range fields.Reverse(f.Chain())[1:] {
+		if fld.Annotation == "" {
+			continue
+		}
+
+		a, err := parquet.ParseAnnotation(fld.Annotation)
+		if err != nil {
+			return "", fmt.Errorf("invalid logical type %s of %s: %s", fld.Annotation, fld.Name, err)
+		}
+
+		ct := "nil"
+		if a.ConvertedType != nil {
+			ct = fmt.Sprintf("parquet.Ptr(sch.ConvertedType_%s)", a.ConvertedType)
+		}
+		out += fmt.Sprintf(", %s(%d, %s, %s)", opt, i, logicalType(a.LogicalType), ct)
+	}
+	return out, nil
+}
+
+// logicalType returns the go literal of lt.
+func logicalType(lt *sch.LogicalType) string {
+	switch {
+	case lt == nil:
+		return "nil"
+	case lt.IsSetSTRING():
+		return "&sch.LogicalType{STRING: &sch.StringType{}}"
+	case lt.IsSetMAP():
+		return "&sch.LogicalType{MAP: &sch.MapType{}}"
+	case lt.IsSetLIST():
+		return "&sch.LogicalType{LIST: &sch.ListType{}}"
+	case lt.IsSetENUM():
+		return "&sch.LogicalType{ENUM: &sch.EnumType{}}"
+	case lt.IsSetDECIMAL():
+		return fmt.Sprintf("&sch.LogicalType{DECIMAL: &sch.DecimalType{Precision: %d, Scale: %d}}", lt.DECIMAL.Precision, lt.DECIMAL.Scale)
+	case lt.IsSetDATE():
+		return "&sch.LogicalType{DATE: &sch.DateType{}}"
+	case lt.IsSetTIME():
+		return fmt.Sprintf("&sch.LogicalType{TIME: &sch.TimeType{IsAdjustedToUTC: %t, Unit: %s}}", lt.TIME.IsAdjustedToUTC, timeUnit(lt.TIME.Unit))
+	case lt.IsSetTIMESTAMP():
+		return fmt.Sprintf("&sch.LogicalType{TIMESTAMP: &sch.TimestampType{IsAdjustedToUTC: %t, Unit: %s}}", lt.TIMESTAMP.IsAdjustedToUTC, timeUnit(lt.TIMESTAMP.Unit))
+	case lt.IsSetINTEGER():
+		return fmt.Sprintf("&sch.LogicalType{INTEGER: &sch.IntType{BitWidth: %d, IsSigned: %t}}", lt.INTEGER.BitWidth, lt.INTEGER.IsSigned)
+	case lt.IsSetUNKNOWN():
+		return "&sch.LogicalType{UNKNOWN: &sch.NullType{}}"
+	case lt.IsSetJSON():
+		return "&sch.LogicalType{JSON: &sch.JsonType{}}"
+	case lt.IsSetBSON():
+		return "&sch.LogicalType{BSON: &sch.BsonType{}}"
+	case lt.IsSetUUID():
+		return "&sch.LogicalType{UUID: &sch.UUIDType{}}"
+	}
+	return "nil"
+}
+
+func timeUnit(u *sch.TimeUnit) string {
+	switch {
+	case u.IsSetMILLIS():
+		return "&sch.TimeUnit{MILLIS: &sch.MilliSeconds{}}"
+	case u.IsSetMICROS():
+		return "&sch.TimeUnit{MICROS: &sch.MicroSeconds{}}"
+	}
+	return "&sch.TimeUnit{NANOS: &sch.NanoSeconds{}}"
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"go/format"
 	"os"
+	"strings"
 	"text/template"
 
 	"github.com/parsyl/parquet"
 	"github.com/parsyl/parquet/cmd/parquetgen/fields"
+	"github.com/parsyl/parquet/cmd/parquetgen/message"
 	"github.com/parsyl/parquet/cmd/parquetgen/parse"
 	"github.com/parsyl/parquet/cmd/parquetgen/structs"
 	sch "github.com/parsyl/parquet/schema"
@@ -26,50 +28,52 @@ var (
 )
 
 // FromStruct generates a parquet reader and writer based on the struct
-// of type 'typ' that is defined in the go file at 'pth'.
+// of type 'typ' that is defined in the go file at 'pth'.  typ can also be
+// a comma separated list of structs, in which case the names of the
+// generated reader, writer and funcs of each struct start with the name
+// of the struct (for example, NewPersonParquetWriter) so they don't clash.
 func FromStruct(pth, outPth, typ, pkg, imp string, ignore bool) error {
-	result, err := parse.Fields(typ, pth)
-	if err != nil {
-		return err
-	}
-
-	if len(result.Errors) > 0 && !ignore {
-		return fmt.Errorf("not generating parquet.go (-ignore set to false), err: %v", result.Errors)
-	}
-
+	typs := strings.Split(typ, ",")
 	i := input{
 		Package: pkg,
-		Type:    typ,
 		Import:  getImport(imp),
-		Parent:  result.Parent,
 	}
 
+	seen := map[string]bool{}
+	for _, t := range typs {
+		t = strings.TrimSpace(t)
+		if seen[t] {
+			return fmt.Errorf("type %s is listed more than once", t)
+		}
+		seen[t] = true
+
+		result, err := parse.Fields(t, pth)
+		if err != nil {
+			return err
+		}
+
+		if len(result.Errors) > 0 && !ignore {
+			return fmt.Errorf("not generating parquet.go (-ignore set to false), err: %v", result.Errors)
+		}
+
+		ti := typeInput{Type: t, Parent: result.Parent}
+		if len(typs) > 1 {
+			ti.Prefix = t
+			ti.Parent.Prefix = t
+		}
+		i.Types = append(i.Types, ti)
+	}
+
+	var err error
 	tmpl := template.New("output").Funcs(funcs)
 	tmpl, err = tmpl.Parse(tpl)
 	if err != nil {
 		return err
 	}
 
-	for _, t := range []string{
-		requiredNumericTpl,
-		optionalNumericTpl,
-		stringTpl,
-		stringOptionalTpl,
-		boolTpl,
-		boolOptionalTpl,
-		newFieldTpl,
-		requiredStatsTpl,
-		optionalStatsTpl,
-		boolStatsTpl,
-		boolOptionalStatsTpl,
-		stringStatsTpl,
-		stringOptionalStatsTpl,
-	} {
-		var err error
-		tmpl, err = tmpl.Parse(t)
-		if err != nil {
-			return err
-		}
+	tmpl, err = tmpl.Parse(newFieldTpl)
+	if err != nil {
+		return err
 	}
 
 	var buf bytes.Buffer
@@ -110,16 +114,39 @@ func FromParquet(parq, pth, outPth, typ, pkg, imp string, ignore bool) error {
 	}
 
 	pf.Close()
+	return fromSchema(parquet.NormalizeSchema(footer.Schema), pth, outPth, typ, pkg, imp, ignore)
+}
+
+// FromSchema generates a go struct, a reader, and a writer based on
+// the parquet schema (in the message syntax, see message.Parse) in
+// the file at 'schemaPth'
+func FromSchema(schemaPth, pth, outPth, typ, pkg, imp string, ignore bool) error {
+	f, err := os.Open(schemaPth)
+	if err != nil {
+		return err
+	}
+
+	schema, err := message.Parse(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("couldn't parse schema: %s", err)
+	}
 
+	return fromSchema(schema, pth, outPth, typ, pkg, imp, ignore)
+}
+
+// fromSchema writes the struct of the schema to pth and
+// then generates the code for the struct.
+func fromSchema(schema []*sch.SchemaElement, pth, outPth, typ, pkg, imp string, ignore bool) error {
 	tmpl := template.New("output").Funcs(funcs)
-	tmpl, err = tmpl.Parse(structTpl)
+	tmpl, err := tmpl.Parse(structTpl)
 	if err != nil {
 		return err
 	}
 
 	n := newStruct{
 		Package: pkg,
-		Structs: structs.Struct(typ, footer.Schema),
+		Structs: structs.Struct(typ, schema),
 	}
 
 	var buf bytes.Buffer
@@ -149,9 +176,15 @@ func FromParquet(parq, pth, outPth, typ, pkg, imp string, ignore bool) error {
 
 type input struct {
 	Package string
-	Type    string
 	Import  string
-	Parent  fields.Field
+	Types   []typeInput
+}
+
+// typeInput is the input of the code that is generated for each struct
+type typeInput struct {
+	Type   string
+	Prefix string
+	Parent fields.Field
 }
 
 func getFieldType(se *sch.SchemaElement) (string, error) {
@@ -175,20 +208,6 @@ func getFieldType(se *sch.SchemaElement) (string, error) {
 	return fmt.Sprintf("%s%s", star, out), nil
 }
 
-func dedupe(flds []fields.Field) []fields.Field {
-	seen := map[string]bool{}
-	out := make([]fields.Field, 0, len(flds))
-	for _, f := range flds {
-		_, ok := seen[f.FieldType()]
-		if !ok {
-			out = append(out, f)
-			seen[f.FieldType()] = true
-		}
-	}
-
-	return out
-}
-
 func getImport(i string) string {
 	if i == "" {
 		return ""
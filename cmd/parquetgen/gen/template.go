@@ -1,502 +1,60 @@
 package gen
 
-var newFieldTpl = `{{define "newField"}}New{{.FieldType}}({{readFuncName .}}, {{writeFuncName .}}, []string{ {{.Path}} }{{if not .Required}}, []int{ {{joinTypes .RepetitionTypes}} }{{end}}, {{compressionFunc .}}(compression)),{{end}}`
+var newFieldTpl = `{{define "newField"}}parquet.New{{if .Required}}Required{{else}}Optional{{end}}Column({{readFuncName .}}, {{writeFuncName .}}, []string{ {{.Path}} }{{if not .Required}}, []int{ {{joinTypes .RepetitionTypes}} }{{end}}, {{compressionFunc .}}(c){{if .ID}}, {{fieldIDFunc .}}({{.ID}}){{end}}{{annotations .}}),{{end}}`
 
 var tpl = `// Code generated by github.com/parsyl/parquet. DO NOT EDIT.
 package {{.Package}}
 
 import (
-	"fmt"
 	"io"
-	"strings"
-	"encoding/binary"
-	"math"
 
-	"github.com/valyala/bytebufferpool"
 	"github.com/parsyl/parquet"
 	sch "github.com/parsyl/parquet/schema"
-
-)
-
-var _ = math.MaxInt32 // to avoid unused import
-
-type compression int
-
-const (
-	compressionUncompressed compression = 0
-	compressionSnappy       compression = 1
-	compressionGzip         compression = 2
-	compressionUnknown      compression = -1
+	{{.Import}}
 )
 
-var buffpool = bytebufferpool.Pool{}
-
-// ParquetWriter reprents a row group
-type ParquetWriter struct {
-	fields []Field
-
-	len int
-
-	// child points to the next page
-	child *ParquetWriter
-
-	// max is the number of Record items that can get written before
-	// a new set of column chunks is written
-	max int
-
-	meta *parquet.Metadata
-	w    io.Writer
-	compression compression
-}
-
-func Fields(compression compression) []Field {
-	return []Field{ {{range .Parent.Fields}}
-		{{template "newField" .}}{{end}}
-	}
-}
-
-{{range $i, $field := .Parent.Fields}}{{readFunc $field}}
-
-{{writeFunc $field}}
-
-{{end}}
-
-func fieldCompression(c compression) func(*parquet.RequiredField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.RequiredFieldUncompressed
-	case compressionSnappy:
-		return parquet.RequiredFieldSnappy
-	case compressionGzip:
-		return parquet.RequiredFieldGzip
-	default:
-		return parquet.RequiredFieldUncompressed
-	}
-}
-
-func optionalFieldCompression(c compression) func(*parquet.OptionalField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.OptionalFieldUncompressed
-	case compressionSnappy:
-		return parquet.OptionalFieldSnappy
-	case compressionGzip:
-		return parquet.OptionalFieldGzip
-	default:
-		return parquet.OptionalFieldUncompressed
-	}
-}
-
-func NewParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
-	return newParquetWriter(w, append(opts, begin)...)
-}
-
-func newParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
-	p := &ParquetWriter{
-		max:         1000,
-		w:           w,
-		compression: compressionSnappy,
-	}
-
-	for _, opt := range opts {
-		if err := opt(p); err != nil {
-			return nil, err
-		}
-	}
-
-	p.fields = Fields(p.compression)
-	if p.meta == nil {
-		ff := Fields(p.compression)
-		schema := make([]parquet.Field, len(ff))
-		for i, f := range ff {
-			schema[i] = f.Schema()
-		}
-		p.meta = parquet.New(schema...)
-	}
-
-	return p, nil
-}
-
-// MaxPageSize is the maximum number of rows in each row groups' page.
-func MaxPageSize(m int) func(*ParquetWriter) error {
-	return func(p *ParquetWriter) error {
-		p.max = m
-		return nil
-	}
-}
-
-var par1 = []byte("PAR1")
-
-func begin(p *ParquetWriter) error {
-	_, err := p.w.Write(par1)
-	return err
-}
-
-func withMeta(m *parquet.Metadata) func(*ParquetWriter) error {
-	return func(p *ParquetWriter) error {
-		p.meta = m
-		return nil
-	}
-}
-
-func Uncompressed(p *ParquetWriter) error {
-	p.compression = compressionUncompressed
-	return nil
-}
-
-func Snappy(p *ParquetWriter) error {
-	p.compression = compressionSnappy
-	return nil
-}
-
-func Gzip(p *ParquetWriter) error {
-	p.compression = compressionGzip
-	return nil
-}
-
-func withCompression(c compression) func(*ParquetWriter) error {
-	return func(p *ParquetWriter) error {
-		p.compression = c
-		return nil
-	}
-}
+{{range .Types}}
+// {{.Prefix}}ParquetWriter writes {{.Parent.StructType}} records to a parquet file
+type {{.Prefix}}ParquetWriter = parquet.Writer[{{.Parent.StructType}}]
 
-func (p *ParquetWriter) Write() error {
-	for i, f := range p.fields {
-		if err := f.Write(p.w, p.meta); err != nil {
-			return err
-		}
-
-		for child := p.child; child != nil; child = child.child {
-			if err := child.fields[i].Write(p.w, p.meta); err != nil {
-				return err
-			}
-		}
-	}
+// {{.Prefix}}ParquetReader reads {{.Parent.StructType}} records from a parquet file
+type {{.Prefix}}ParquetReader = parquet.Reader[{{.Parent.StructType}}]
 
-	p.fields = Fields(p.compression)
-	p.child = nil
-	p.len = 0
-
-	schema := make([]parquet.Field, len(p.fields))
-	for i, f := range p.fields {
-		schema[i] = f.Schema()
-	}
-	p.meta.StartRowGroup(schema...)
-	return nil
+// New{{.Prefix}}ParquetWriter creates a writer for {{.Parent.StructType}} records
+func New{{.Prefix}}ParquetWriter(w io.Writer, opts ...parquet.WriterOption) (*{{.Prefix}}ParquetWriter, error) {
+	return parquet.NewCodecWriter[{{.Parent.StructType}}](w, {{.Type}}Codec{}, opts...)
 }
 
-func (p *ParquetWriter) Close() error {
-	if err := p.meta.Footer(p.w); err != nil {
-		return err
-	}
-
-	_, err := p.w.Write(par1)
-	return err
+// Open{{.Prefix}}ForAppend creates a writer that adds row groups to the parquet file
+// in rws, which must have the schema of {{.Parent.StructType}} (see parquet.OpenForAppend).
+func Open{{.Prefix}}ForAppend(rws io.ReadWriteSeeker, opts ...parquet.WriterOption) (*{{.Prefix}}ParquetWriter, error) {
+	return parquet.OpenCodecForAppend[{{.Parent.StructType}}](rws, {{.Type}}Codec{}, opts...)
 }
 
-func (p *ParquetWriter) Add(rec {{.Parent.StructType}}) {
-	if p.len == p.max {
-		if p.child == nil {
-			// an error can't happen here
-			p.child, _ = newParquetWriter(p.w, MaxPageSize(p.max), withMeta(p.meta), withCompression(p.compression))
-		}
-
-		p.child.Add(rec)
-		return
-	}
-
-	p.meta.NextDoc()
-	for _, f := range p.fields {
-		f.Add(rec)
-	}
-
-	p.len++
+// New{{.Prefix}}ParquetReader creates a reader for {{.Parent.StructType}} records
+func New{{.Prefix}}ParquetReader(r io.ReadSeeker, opts ...parquet.ReaderOption) (*{{.Prefix}}ParquetReader, error) {
+	return parquet.NewCodecReader[{{.Parent.StructType}}](r, {{.Type}}Codec{}, opts...)
 }
 
-type Field interface {
-	Add(r {{.Parent.StructType}})
-	Write(w io.Writer, meta *parquet.Metadata) error
-	Schema() parquet.Field
-	Scan(r *{{.Parent.StructType}})
-	Read(r io.ReadSeeker, pg parquet.Page) error
-	Name() string
-	Levels() ([]uint8, []uint8)
+// New{{.Prefix}}ParquetReaderAt creates a reader for the parquet file of the given size.
+// The file is only read with ReadAt so several readers (for example, each one
+// reading a different row group) can share r from different goroutines.
+func New{{.Prefix}}ParquetReaderAt(r io.ReaderAt, size int64, opts ...parquet.ReaderOption) (*{{.Prefix}}ParquetReader, error) {
+	return parquet.NewCodecReaderAt[{{.Parent.StructType}}](r, size, {{.Type}}Codec{}, opts...)
 }
 
-func getFields(ff []Field) map[string]Field {
-	m := make(map[string]Field, len(ff))
-	for _, f := range ff {
-		m[f.Name()] = f
-	}
-	return m
-}
+// {{.Type}}Codec is the parquet.Codec of {{.Parent.StructType}}
+type {{.Type}}Codec struct{}
 
-func NewParquetReader(r io.ReadSeeker, opts ...func(*ParquetReader)) (*ParquetReader, error) {
-	ff := Fields(compressionUnknown)
-	pr := &ParquetReader{
-		r: r,
-	}
-
-	for _, opt := range opts {
-		opt(pr)
-	}
-
-	schema := make([]parquet.Field, len(ff))
-	for i, f := range ff {
-		pr.fieldNames = append(pr.fieldNames, f.Name())
-		schema[i] = f.Schema()
-	}
-
-	meta := parquet.New(schema...)
-	if err := meta.ReadFooter(r); err != nil {
-		return nil, err
-	}
-	pr.rows = meta.Rows()
-	var err error
-	pr.pages, err = meta.Pages()
-	if err != nil {
-		return nil, err
-	}
-
-	pr.rowGroups = meta.RowGroups()
-	_, err = r.Seek(4, io.SeekStart)
-	if err != nil {
-		return nil, err
-	}
-	pr.meta = meta
-
-	return pr, pr.readRowGroup()
-}
-
-func readerIndex(i int) func(*ParquetReader) {
-	return func(p *ParquetReader) {
-		p.index = i
-	}
-}
-
-// ParquetReader reads one page from a row group.
-type ParquetReader struct {
-	fields         map[string]Field
-	fieldNames     []string
-	index          int
-	cursor         int64
-	rows           int64
-	rowGroupCursor int64
-	rowGroupCount  int64
-	pages          map[string][]parquet.Page
-	meta           *parquet.Metadata
-	err            error
-
-	r         io.ReadSeeker
-	rowGroups []parquet.RowGroup
-}
-
-type Levels struct {
-	Name string
-	Defs []uint8
-	Reps []uint8
-}
-
-func (p *ParquetReader) Levels() []Levels {
-	var out []Levels
-	//for {
-	for _, name := range p.fieldNames {
-		f := p.fields[name]
-		d, r := f.Levels()
-		out = append(out, Levels{Name: f.Name(), Defs: d, Reps: r})
-	}
-	//	if err := p.readRowGroup(); err != nil {
-	//		break
-	//	}
-	//}
-	return out
-}
-
-func (p *ParquetReader) Error() error {
-	return p.err
-}
-
-func (p *ParquetReader) readRowGroup() error {
-	p.rowGroupCursor = 0
-
-	if len(p.rowGroups) == 0 {
-		p.rowGroupCount = 0
-		return nil
-	}
-
-	rg := p.rowGroups[0]
-	p.fields = getFields(Fields(compressionUnknown))
-	p.rowGroupCount = rg.Rows
-	p.rowGroupCursor = 0
-	for _, col := range rg.Columns() {
-		name := strings.Join(col.MetaData.PathInSchema, ".")
-		f, ok := p.fields[name]
-		if !ok {
-			return fmt.Errorf("unknown field: %s", name)
-		}
-		pages := p.pages[name]
-		if len(pages) <= p.index {
-			break
-		}
-
-		pg := pages[0]
-		if err := f.Read(p.r, pg); err != nil {
-			return fmt.Errorf("unable to read field %s, err: %s", f.Name(), err)
-		}
-		p.pages[name] = p.pages[name][1:]
-	}
-	p.rowGroups = p.rowGroups[1:]
-	return nil
-}
-
-func (p *ParquetReader) Rows() int64 {
-	return p.rows
-}
-
-func (p *ParquetReader) Next() bool {
-	if p.err == nil && p.cursor >= p.rows {
-		return false
-	}
-	if p.rowGroupCursor >= p.rowGroupCount {
-		p.err = p.readRowGroup()
-		if p.err != nil {
-			return false
-		}
-	}
-
-	p.cursor++
-	p.rowGroupCursor++
-	return true
-}
-
-func (p *ParquetReader) Scan(x *{{.Parent.StructType}}) {
-	if p.err != nil {
-		return
-	}
-
-	for _, name := range p.fieldNames {
-		f := p.fields[name]
-		f.Scan(x)
-	}
-}
-
-{{range dedupe .Parent.Fields}}
-{{if eq .Category "numeric"}}
-{{ template "numericField" .}}
-{{end}}
-{{if eq .Category "numericOptional"}}
-{{ template "optionalField" .}}
-{{end}}
-{{if eq .Category "string"}}
-{{ template "stringField" .}}
-{{end}}
-{{if eq .Category "stringOptional"}}
-{{ template "stringOptionalField" .}}
-{{end}}
-{{if eq .Category "bool"}}
-{{ template "boolField" .}}
-{{end}}
-{{if eq .Category "boolOptional"}}
-{{ template "boolOptionalField" .}}
-{{end}}
-{{end}}
-
-{{range dedupe .Parent.Fields}}
-{{if eq .Category "numeric"}}
-{{ template "requiredStats" .}}
-{{end}}
-{{if eq .Category "numericOptional"}}
-{{ template "optionalStats" .}}
-{{end}}
-{{if eq .Category "string"}}
-{{ template "stringStats" .}}
-{{end}}
-{{if eq .Category "stringOptional"}}
-{{ template "stringOptionalStats" .}}
-{{end}}
-{{if eq .Category "bool"}}
-{{ template "boolStats" .}}
-{{end}}
-{{if eq .Category "boolOptional"}}
-{{ template "boolOptionalStats" .}}
-{{end}}
-{{end}}
-
-func pint32(i int32) *int32       { return &i }
-func puint32(i uint32) *uint32    { return &i }
-func pint64(i int64) *int64       { return &i }
-func puint64(i uint64) *uint64    { return &i }
-func pbool(b bool) *bool          { return &b }
-func pstring(s string) *string    { return &s }
-func pfloat32(f float32) *float32 { return &f }
-func pfloat64(f float64) *float64 { return &f }
-
-// keeps track of the indices of repeated fields
-// that have already been handled by a previous field
-type indices []int
-
-func (i indices) rep(rep uint8) {
-	if rep > 0 {
-		r := int(rep) - 1
-		i[r] = i[r] + 1
-		for j := int(rep); j < len(i); j++ {
-			i[j] = 0
-		}
-	}
-}
-
-func maxDef(types []int) uint8 {
-	var out uint8
-	for _, typ := range types {
-		if typ > 0 {
-			out++
-		}
+// Fields creates the columns of {{.Parent.StructType}}
+func ({{.Type}}Codec) Fields(c sch.CompressionCodec) []parquet.Column[{{.Parent.StructType}}] {
+	return []parquet.Column[{{.Parent.StructType}}]{ {{range .Parent.Fields}}
+		{{template "newField" .}}{{end}}
 	}
-	return out
-}
-
-func Int32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-}
-
-func Uint32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_32
-	se.ConvertedType = &ct
-}
-
-func Int64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-}
-
-func Uint64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_64
-	se.ConvertedType = &ct
-}
-
-func Float32Type(se *sch.SchemaElement) {
-	t := sch.Type_FLOAT
-	se.Type = &t
 }
 
-func Float64Type(se *sch.SchemaElement) {
-	t := sch.Type_DOUBLE
-	se.Type = &t
-}
+{{range $i, $field := .Parent.Fields}}{{readFunc $field}}
 
-func BoolType(se *sch.SchemaElement) {
-	t := sch.Type_BOOLEAN
-	se.Type = &t
-}
+{{writeFunc $field}}
 
-func StringType(se *sch.SchemaElement) {
-	t := sch.Type_BYTE_ARRAY
-	se.Type = &t
-}
-`
+{{end}}{{end}}`
@@ -15,7 +15,7 @@ import (
 var (
 	metadata     = flag.Bool("metadata", false, "print the metadata of a parquet file (-parquet) and exit")
 	pageheaders  = flag.Bool("pageheaders", false, "print the page headers of a parquet file (-parquet) and exit (also prints the metadata)")
-	typ          = flag.String("type", "", "name of the struct that will used for writing and reading")
+	typ          = flag.String("type", "", "name of the struct that will used for writing and reading (or a comma separated list of structs, see the README)")
 	pkg          = flag.String("package", "", "package of the generated code")
 	imp          = flag.String("import", "", "import statement of -type if it doesn't live in -package")
 	pth          = flag.String("input", "", "path to the go file that defines -type")
@@ -23,20 +23,48 @@ var (
 	ignore       = flag.Bool("ignore", true, "ignore unsupported fields in -type, otherwise log.Fatal is called when an unsupported type is encountered")
 	parq         = flag.String("parquet", "", "path to a parquet file (if you are generating code based on an existing parquet file or printing the file metadata or page headers)")
 	structOutPth = flag.String("struct-output", "generated_struct.go", "name of the file that is produced, defaults to parquet.go")
+	schemaPth    = flag.String("schema", "", "path to a file with a parquet schema in the message syntax (see the schema subcommand) to generate the struct, reader and writer from")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		if err := merge(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && (os.Args[1] == "cat" || os.Args[1] == "head") {
+		if err := cat(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "schema" {
+		if err := schema(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	flag.Parse()
 
 	if *pth != "" && *parq != "" {
 		log.Fatal("choose -parquet or -input, but not both")
 	}
 
+	if *schemaPth != "" && (*pth != "" || *parq != "") {
+		log.Fatal("choose -schema, -parquet or -input, but only one of them")
+	}
+
 	var err error
 	if *metadata {
 		readFooter()
 	} else if *pageheaders {
 		readPageHeaders()
+	} else if *schemaPth != "" {
+		err = gen.FromSchema(*schemaPth, *structOutPth, *outPth, *typ, *pkg, *imp, *ignore)
 	} else if *parq == "" {
 		err = gen.FromStruct(*pth, *outPth, *typ, *pkg, *imp, *ignore)
 	} else {
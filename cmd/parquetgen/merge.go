@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/parsyl/parquet"
+)
+
+// merge is the merge subcommand:
+//
+//	parquetgen merge -output merged.parquet a.parquet b.parquet
+func merge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	out := fs.String("output", "", "path of the merged parquet file")
+	coalesce := fs.Int64("coalesce", 0, "merge consecutive row groups as long as their total compressed size is at most this many bytes (0 keeps the row groups as they are)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: parquetgen merge -output merged.parquet [-coalesce bytes] file.parquet...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *out == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	srcs := make([]io.ReadSeeker, fs.NArg())
+	for i, pth := range fs.Args() {
+		f, err := os.Open(pth)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		srcs[i] = f
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+
+	m := parquet.Merger{CoalesceBytes: *coalesce}
+	if err := m.Merge(f, srcs...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
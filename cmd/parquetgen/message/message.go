@@ -0,0 +1,169 @@
+// Package message renders the schema of a parquet file in the parquet
+// message syntax, and parses schemas written in it:
+//
+//	message root {
+//	  required int64 id = 1; // max def 0, max rep 0
+//	  optional group address {
+//	    required binary city (STRING); // max def 1, max rep 0
+//	  }
+//	}
+package message
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/parsyl/parquet"
+	"github.com/parsyl/parquet/cmd/parquetgen/fields"
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// Format renders the schema elements of a file's footer (the first
+// element is the root).  Each column is followed by a comment with its
+// max definition and repetition levels (see fields.Field.MaxDef).
+func Format(schema []*sch.SchemaElement) (string, error) {
+	if len(schema) == 0 {
+		return "", fmt.Errorf("the schema has no root")
+	}
+
+	schema = parquet.NormalizeSchema(schema)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "message %s {\n", schema[0].Name)
+	n, err := formatChildren(&buf, schema[0], schema[1:], &fields.Field{}, 1)
+	if err != nil {
+		return "", err
+	}
+
+	if n != len(schema)-1 {
+		return "", fmt.Errorf("the schema has %d elements that aren't in the root's groups", len(schema)-1-n)
+	}
+
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
+
+// formatChildren writes the children of the group parent, which start at
+// elems[0], and returns the number of elements that belong to the group.
+func formatChildren(buf *bytes.Buffer, parent *sch.SchemaElement, elems []*sch.SchemaElement, pf *fields.Field, depth int) (int, error) {
+	indent := strings.Repeat("  ", depth)
+	var i int
+	for c := 0; c < int(parent.GetNumChildren()); c++ {
+		if i >= len(elems) {
+			return 0, fmt.Errorf("group %s has %d children, found %d", parent.Name, parent.GetNumChildren(), c)
+		}
+
+		e := elems[i]
+		i++
+		f := fields.Field{Name: e.Name, RepetitionType: repetitionType(e), Parent: pf}
+		if e.GetNumChildren() > 0 {
+			fmt.Fprintf(buf, "%s%s group %s%s {\n", indent, repetition(e), e.Name, suffix(e))
+			n, err := formatChildren(buf, e, elems[i:], &f, depth+1)
+			if err != nil {
+				return 0, err
+			}
+			i += n
+			fmt.Fprintf(buf, "%s}\n", indent)
+			continue
+		}
+
+		if !e.IsSetType() {
+			return 0, fmt.Errorf("column %s has no type", e.Name)
+		}
+
+		fmt.Fprintf(buf, "%s%s %s %s%s; // max def %d, max rep %d\n", indent, repetition(e), physicalType(e), e.Name, suffix(e), f.MaxDef(), f.MaxRep())
+	}
+	return i, nil
+}
+
+func repetitionType(e *sch.SchemaElement) fields.RepetitionType {
+	switch e.GetRepetitionType() {
+	case sch.FieldRepetitionType_OPTIONAL:
+		return fields.Optional
+	case sch.FieldRepetitionType_REPEATED:
+		return fields.Repeated
+	}
+	return fields.Required
+}
+
+func repetition(e *sch.SchemaElement) string {
+	return strings.ToLower(e.GetRepetitionType().String())
+}
+
+func physicalType(e *sch.SchemaElement) string {
+	switch e.GetType() {
+	case sch.Type_BYTE_ARRAY:
+		return "binary"
+	case sch.Type_FIXED_LEN_BYTE_ARRAY:
+		return fmt.Sprintf("fixed_len_byte_array(%d)", e.GetTypeLength())
+	}
+	return strings.ToLower(e.GetType().String())
+}
+
+// suffix is the annotation (the logical or converted type) and
+// the field_id that come after the name of a column or group.
+func suffix(e *sch.SchemaElement) string {
+	var out string
+	if a := Annotation(e); a != "" {
+		out = fmt.Sprintf(" (%s)", a)
+	}
+
+	if e.IsSetFieldID() {
+		out = fmt.Sprintf("%s = %d", out, e.GetFieldID())
+	}
+	return out
+}
+
+// Annotation returns the logical type of e or, for files that
+// only have a converted type, its converted type.
+func Annotation(e *sch.SchemaElement) string {
+	if lt := e.GetLogicalType(); lt != nil {
+		switch {
+		case lt.IsSetSTRING():
+			return "STRING"
+		case lt.IsSetMAP():
+			return "MAP"
+		case lt.IsSetLIST():
+			return "LIST"
+		case lt.IsSetENUM():
+			return "ENUM"
+		case lt.IsSetDECIMAL():
+			return fmt.Sprintf("DECIMAL(%d,%d)", lt.DECIMAL.Precision, lt.DECIMAL.Scale)
+		case lt.IsSetDATE():
+			return "DATE"
+		case lt.IsSetTIME():
+			return fmt.Sprintf("TIME(%s,%t)", timeUnit(lt.TIME.Unit), lt.TIME.IsAdjustedToUTC)
+		case lt.IsSetTIMESTAMP():
+			return fmt.Sprintf("TIMESTAMP(%s,%t)", timeUnit(lt.TIMESTAMP.Unit), lt.TIMESTAMP.IsAdjustedToUTC)
+		case lt.IsSetINTEGER():
+			return fmt.Sprintf("INTEGER(%d,%t)", lt.INTEGER.BitWidth, lt.INTEGER.IsSigned)
+		case lt.IsSetUNKNOWN():
+			return "UNKNOWN"
+		case lt.IsSetJSON():
+			return "JSON"
+		case lt.IsSetBSON():
+			return "BSON"
+		case lt.IsSetUUID():
+			return "UUID"
+		}
+	}
+
+	if !e.IsSetConvertedType() {
+		return ""
+	}
+
+	if e.GetConvertedType() == sch.ConvertedType_DECIMAL {
+		return fmt.Sprintf("DECIMAL(%d,%d)", e.GetPrecision(), e.GetScale())
+	}
+	return e.GetConvertedType().String()
+}
+
+func timeUnit(u *sch.TimeUnit) string {
+	switch {
+	case u.IsSetMILLIS():
+		return "MILLIS"
+	case u.IsSetMICROS():
+		return "MICROS"
+	}
+	return "NANOS"
+}
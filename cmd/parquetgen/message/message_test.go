@@ -0,0 +1,178 @@
+package message_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/parsyl/parquet"
+	"github.com/parsyl/parquet/cmd/parquetgen/message"
+	sch "github.com/parsyl/parquet/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schema   []*sch.SchemaElement
+		expected string
+		err      string
+	}{
+		{
+			name: "flat",
+			schema: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(3)},
+				{Name: "id", Type: pt(sch.Type_INT64), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), FieldID: pint32(1)},
+				{Name: "name", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), LogicalType: &sch.LogicalType{STRING: &sch.StringType{}}},
+				{Name: "n", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), ConvertedType: pct(sch.ConvertedType_UINT_32)},
+			},
+			expected: `message root {
+  required int64 id = 1; // max def 0, max rep 0
+  optional binary name (STRING); // max def 1, max rep 0
+  optional int32 n (UINT_32); // max def 1, max rep 0
+}
+`,
+		},
+		{
+			name: "nested",
+			schema: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(3)},
+				{Name: "address", RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), NumChildren: pint32(2), FieldID: pint32(2)},
+				{Name: "city", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), ConvertedType: pct(sch.ConvertedType_UTF8)},
+				{Name: "zip", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL)},
+				{Name: "tags", RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), NumChildren: pint32(1), LogicalType: &sch.LogicalType{LIST: &sch.ListType{}}},
+				{Name: "list", RepetitionType: prt(sch.FieldRepetitionType_REPEATED), NumChildren: pint32(1)},
+				{Name: "element", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL)},
+				{Name: "created", Type: pt(sch.Type_INT64), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{TIMESTAMP: &sch.TimestampType{IsAdjustedToUTC: true, Unit: &sch.TimeUnit{MILLIS: &sch.MilliSeconds{}}}}},
+			},
+			expected: `message root {
+  optional group address = 2 {
+    required binary city (UTF8); // max def 1, max rep 0
+    optional int32 zip; // max def 2, max rep 0
+  }
+  optional group tags (LIST) {
+    repeated group list {
+      optional binary element; // max def 3, max rep 1
+    }
+  }
+  required int64 created (TIMESTAMP(MILLIS,true)); // max def 0, max rep 0
+}
+`,
+		},
+		{
+			name: "logical types",
+			schema: []*sch.SchemaElement{
+				{Name: "schema", NumChildren: pint32(4)},
+				{Name: "price", Type: pt(sch.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: pint32(16), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{DECIMAL: &sch.DecimalType{Precision: 38, Scale: 9}}},
+				{Name: "cost", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), ConvertedType: pct(sch.ConvertedType_DECIMAL), Precision: pint32(9), Scale: pint32(2)},
+				{Name: "small", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{INTEGER: &sch.IntType{BitWidth: 8, IsSigned: false}}},
+				{Name: "at", Type: pt(sch.Type_INT64), RepetitionType: prt(sch.FieldRepetitionType_REPEATED), LogicalType: &sch.LogicalType{TIME: &sch.TimeType{Unit: &sch.TimeUnit{NANOS: &sch.NanoSeconds{}}}}},
+			},
+			expected: `message schema {
+  required fixed_len_byte_array(16) price (DECIMAL(38,9)); // max def 0, max rep 0
+  required int32 cost (DECIMAL(9,2)); // max def 0, max rep 0
+  required int32 small (INTEGER(8,false)); // max def 0, max rep 0
+  repeated int64 at (TIME(NANOS,false)); // max def 1, max rep 1
+}
+`,
+		},
+		{
+			// files written by earlier versions of this package count
+			// the leaves below a group and every group as a child of
+			// the root
+			name: "legacy num_children",
+			schema: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(3)},
+				{Name: "id", Type: pt(sch.Type_INT64), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED)},
+				{Name: "friends", RepetitionType: prt(sch.FieldRepetitionType_REPEATED), NumChildren: pint32(3)},
+				{Name: "name", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED)},
+				{Name: "hobby", RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), NumChildren: pint32(2)},
+				{Name: "name", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED)},
+				{Name: "difficulty", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL)},
+			},
+			expected: `message root {
+  required int64 id; // max def 0, max rep 0
+  repeated group friends {
+    required binary name; // max def 1, max rep 1
+    optional group hobby {
+      required binary name; // max def 2, max rep 1
+      optional int32 difficulty; // max def 3, max rep 1
+    }
+  }
+}
+`,
+		},
+		{
+			name: "missing children",
+			schema: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(2)},
+				{Name: "id", Type: pt(sch.Type_INT64), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED)},
+			},
+			err: "group root has 2 children, found 1",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%02d %s", i, tc.name), func(t *testing.T) {
+			s, err := message.Format(tc.schema)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, s)
+		})
+	}
+}
+
+func TestFormatFile(t *testing.T) {
+	s, err := parquet.NewSchema(
+		parquet.Int64Node("id").WithID(1),
+		parquet.GroupNode("friends",
+			parquet.StringNode("name"),
+			parquet.GroupNode("hobby",
+				parquet.Int32Node("difficulty").Optional(),
+			).Optional(),
+		).Repeated(),
+	)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := parquet.NewDynamicWriter(&buf, s)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	out, err := message.Format(footer.Schema)
+	require.NoError(t, err)
+	assert.Equal(t, `message root {
+  required int64 id = 1; // max def 0, max rep 0
+  repeated group friends {
+    required binary name; // max def 1, max rep 1
+    optional group hobby {
+      optional int32 difficulty; // max def 3, max rep 1
+    }
+  }
+}
+`, out)
+}
+
+func pint32(i int32) *int32 {
+	return &i
+}
+
+func prt(rt sch.FieldRepetitionType) *sch.FieldRepetitionType {
+	return &rt
+}
+
+func pt(t sch.Type) *sch.Type {
+	return &t
+}
+
+func pct(t sch.ConvertedType) *sch.ConvertedType {
+	return &t
+}
@@ -0,0 +1,347 @@
+package message
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/parsyl/parquet"
+	sch "github.com/parsyl/parquet/schema"
+)
+
+var (
+	physicalTypes = map[string]sch.Type{
+		"boolean":              sch.Type_BOOLEAN,
+		"int32":                sch.Type_INT32,
+		"int64":                sch.Type_INT64,
+		"int96":                sch.Type_INT96,
+		"float":                sch.Type_FLOAT,
+		"double":               sch.Type_DOUBLE,
+		"binary":               sch.Type_BYTE_ARRAY,
+		"fixed_len_byte_array": sch.Type_FIXED_LEN_BYTE_ARRAY,
+	}
+
+	repetitionTypes = map[string]sch.FieldRepetitionType{
+		"required": sch.FieldRepetitionType_REQUIRED,
+		"optional": sch.FieldRepetitionType_OPTIONAL,
+		"repeated": sch.FieldRepetitionType_REPEATED,
+	}
+)
+
+// Parse parses a schema in the parquet message syntax (see Format) into
+// the schema elements of a file's footer.  Comments (from // to the end
+// of the line) are ignored, so the output of Format can be parsed.  The
+// annotations set both the logical type and (if there is one) the
+// converted type of a column or group.
+func Parse(r io.Reader) ([]*sch.SchemaElement, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{toks: tokenize(string(b))}
+	if err := p.expect("message"); err != nil {
+		return nil, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	root := &sch.SchemaElement{Name: name}
+	p.out = append(p.out, root)
+	if err := p.group(root); err != nil {
+		return nil, err
+	}
+
+	if t := p.next(); t.text != "" {
+		return nil, t.errorf("expected the end of the schema, found %q", t.text)
+	}
+	return p.out, nil
+}
+
+type token struct {
+	text string
+	line int
+}
+
+func (t token) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", t.line, fmt.Sprintf(format, args...))
+}
+
+// tokenize splits s into names (which include numbers) and the
+// punctuation of the syntax.
+func tokenize(s string) []token {
+	var out []token
+	line := 1
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(s[i:], "//"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.ContainsRune("{}();=,", rune(c)):
+			out = append(out, token{text: string(c), line: line})
+			i++
+		default:
+			j := i
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && !strings.ContainsRune("{}();=,/", rune(s[j])) {
+				j++
+			}
+			if j == i {
+				// a '/' that doesn't start a comment
+				j++
+			}
+			out = append(out, token{text: s[i:j], line: line})
+			i = j
+		}
+	}
+	return out
+}
+
+type parser struct {
+	toks []token
+	pos  int
+	out  []*sch.SchemaElement
+}
+
+// next returns the next token (whose text is empty at the end)
+func (p *parser) next() token {
+	if p.pos >= len(p.toks) {
+		var line int
+		if len(p.toks) > 0 {
+			line = p.toks[len(p.toks)-1].line
+		}
+		return token{line: line}
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	return t
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.toks) {
+		return ""
+	}
+	return p.toks[p.pos].text
+}
+
+func (p *parser) expect(s string) error {
+	if t := p.next(); t.text != s {
+		return t.errorf("expected %q, found %q", s, t.text)
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.next()
+	if t.text == "" || strings.ContainsAny(t.text, "{}();=,") {
+		return "", t.errorf("expected a name, found %q", t.text)
+	}
+	return t.text, nil
+}
+
+func (p *parser) int(bits int) (int64, error) {
+	t := p.next()
+	i, err := strconv.ParseInt(t.text, 10, bits)
+	if err != nil {
+		return 0, t.errorf("expected a number, found %q", t.text)
+	}
+	return i, nil
+}
+
+// group parses the fields between the braces of the group g
+func (p *parser) group(g *sch.SchemaElement) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	var n int32
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			return p.next().errorf("expected \"}\" at the end of %s", g.Name)
+		}
+
+		if err := p.field(); err != nil {
+			return err
+		}
+		n++
+	}
+	end := p.next()
+
+	if n == 0 {
+		return end.errorf("group %s has no fields", g.Name)
+	}
+	g.NumChildren = &n
+	return nil
+}
+
+// field parses a column or a group (and its fields):
+//
+//	<repetition> <type> <name> [(<annotation>)] [= <id>];
+//	<repetition> group <name> [(<annotation>)] [= <id>] { ... }
+func (p *parser) field() error {
+	start := p.next()
+	rt, ok := repetitionTypes[strings.ToLower(start.text)]
+	if !ok {
+		return start.errorf("expected required, optional or repeated, found %q", start.text)
+	}
+
+	e := &sch.SchemaElement{RepetitionType: &rt}
+	p.out = append(p.out, e)
+
+	t := p.next()
+	typ := strings.ToLower(t.text)
+	if typ != "group" {
+		pt, ok := physicalTypes[typ]
+		if !ok {
+			return t.errorf("unknown type %q", t.text)
+		}
+		e.Type = &pt
+
+		if pt == sch.Type_FIXED_LEN_BYTE_ARRAY {
+			if err := p.expect("("); err != nil {
+				return err
+			}
+
+			n, err := p.int(32)
+			if err != nil {
+				return err
+			}
+			l := int32(n)
+			e.TypeLength = &l
+
+			if err := p.expect(")"); err != nil {
+				return err
+			}
+		}
+	}
+
+	var err error
+	if e.Name, err = p.name(); err != nil {
+		return err
+	}
+
+	if p.peek() == "(" {
+		p.next()
+		if err := p.annotation(e); err != nil {
+			return err
+		}
+	}
+
+	if p.peek() == "=" {
+		p.next()
+		id, err := p.int(32)
+		if err != nil {
+			return err
+		}
+		i := int32(id)
+		e.FieldID = &i
+	}
+
+	if e.Type != nil {
+		return p.expect(";")
+	}
+
+	i := len(p.out)
+	if err := p.group(e); err != nil {
+		return err
+	}
+
+	if p.peek() == ";" {
+		p.next()
+	}
+	if err := checkGroup(e, p.out[i:]); err != nil {
+		return start.errorf("%s", err)
+	}
+	return nil
+}
+
+// annotation parses the logical (or converted) type of e, which
+// comes after the '(' and ends with a ')'.
+func (p *parser) annotation(e *sch.SchemaElement) error {
+	t := p.next()
+	name := strings.ToUpper(t.text)
+	var args []token
+	if p.peek() == "(" {
+		p.next()
+		for p.peek() != ")" {
+			a := p.next()
+			if a.text == "" {
+				return a.errorf("expected \")\" after the arguments of %s", name)
+			}
+
+			if a.text != "," {
+				args = append(args, a)
+			}
+		}
+		p.next()
+	}
+
+	if err := p.expect(")"); err != nil {
+		return err
+	}
+
+	if err := setAnnotation(e, name, args); err != nil {
+		return t.errorf("invalid annotation %s of %s, err: %s", name, e.Name, err)
+	}
+	return nil
+}
+
+func setAnnotation(e *sch.SchemaElement, name string, args []token) error {
+	strs := make([]string, len(args))
+	for i, a := range args {
+		strs[i] = a.text
+	}
+
+	a, err := parquet.NewAnnotation(name, strs...)
+	if err != nil {
+		return err
+	}
+
+	a.Set(e)
+	return nil
+}
+
+// checkGroup checks that a LIST or MAP group has the structure that
+// the parquet spec requires: a single repeated field (which, for a
+// MAP, is a group with a required key and an optional value).
+// children are the elements below the group.
+func checkGroup(g *sch.SchemaElement, children []*sch.SchemaElement) error {
+	if g.GetLogicalType() == nil || !(g.LogicalType.IsSetLIST() || g.LogicalType.IsSetMAP()) {
+		return nil
+	}
+
+	typ := "LIST"
+	if g.LogicalType.IsSetMAP() {
+		typ = "MAP"
+	}
+
+	if g.GetRepetitionType() == sch.FieldRepetitionType_REPEATED {
+		return fmt.Errorf("%s group %s can't be repeated", typ, g.Name)
+	}
+
+	r := children[0]
+	if g.GetNumChildren() != 1 || r.GetRepetitionType() != sch.FieldRepetitionType_REPEATED {
+		return fmt.Errorf("%s group %s must have a single repeated field", typ, g.Name)
+	}
+
+	if typ == "LIST" {
+		return nil
+	}
+
+	n := r.GetNumChildren()
+	if n == 0 || n > 2 || children[1].Name != "key" || children[1].GetRepetitionType() != sch.FieldRepetitionType_REQUIRED {
+		return fmt.Errorf("MAP group %s must have a repeated group with a required key and an optional value", g.Name)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package message_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/parsyl/parquet/cmd/parquetgen/message"
+	sch "github.com/parsyl/parquet/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schema   string
+		expected []*sch.SchemaElement
+		err      string
+	}{
+		{
+			name: "flat",
+			schema: `// the users table
+message root {
+  required int64 id = 1;
+  optional binary name (STRING); // the display name
+  optional int32 n (UINT_32);
+}`,
+			expected: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(3)},
+				{Name: "id", Type: pt(sch.Type_INT64), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), FieldID: pint32(1)},
+				{Name: "name", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), LogicalType: &sch.LogicalType{STRING: &sch.StringType{}}, ConvertedType: pct(sch.ConvertedType_UTF8)},
+				{Name: "n", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), LogicalType: &sch.LogicalType{INTEGER: &sch.IntType{BitWidth: 32}}, ConvertedType: pct(sch.ConvertedType_UINT_32)},
+			},
+		},
+		{
+			name: "list and map",
+			schema: `message root {
+  optional group tags (LIST) {
+    repeated group list {
+      optional binary element;
+    }
+  }
+  required group attrs (MAP) = 4 {
+    repeated group key_value {
+      required binary key (STRING);
+      optional int64 value;
+    }
+  }
+}`,
+			expected: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(2)},
+				{Name: "tags", RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), NumChildren: pint32(1), LogicalType: &sch.LogicalType{LIST: &sch.ListType{}}, ConvertedType: pct(sch.ConvertedType_LIST)},
+				{Name: "list", RepetitionType: prt(sch.FieldRepetitionType_REPEATED), NumChildren: pint32(1)},
+				{Name: "element", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL)},
+				{Name: "attrs", RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), NumChildren: pint32(1), FieldID: pint32(4), LogicalType: &sch.LogicalType{MAP: &sch.MapType{}}, ConvertedType: pct(sch.ConvertedType_MAP)},
+				{Name: "key_value", RepetitionType: prt(sch.FieldRepetitionType_REPEATED), NumChildren: pint32(2)},
+				{Name: "key", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{STRING: &sch.StringType{}}, ConvertedType: pct(sch.ConvertedType_UTF8)},
+				{Name: "value", Type: pt(sch.Type_INT64), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL)},
+			},
+		},
+		{
+			name: "logical types",
+			schema: `message schema {
+  required fixed_len_byte_array(16) price (DECIMAL(38,9));
+  required int64 at (TIMESTAMP(MICROS,true));
+  required int32 day (DATE);
+}`,
+			expected: []*sch.SchemaElement{
+				{Name: "schema", NumChildren: pint32(3)},
+				{Name: "price", Type: pt(sch.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: pint32(16), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{DECIMAL: &sch.DecimalType{Precision: 38, Scale: 9}}, ConvertedType: pct(sch.ConvertedType_DECIMAL), Precision: pint32(38), Scale: pint32(9)},
+				{Name: "at", Type: pt(sch.Type_INT64), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{TIMESTAMP: &sch.TimestampType{IsAdjustedToUTC: true, Unit: &sch.TimeUnit{MICROS: &sch.MicroSeconds{}}}}, ConvertedType: pct(sch.ConvertedType_TIMESTAMP_MICROS)},
+				{Name: "day", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{DATE: &sch.DateType{}}, ConvertedType: pct(sch.ConvertedType_DATE)},
+			},
+		},
+		{
+			name: "unknown type",
+			schema: `message root {
+  required int64 id;
+  required varchar name;
+}`,
+			err: `line 3: unknown type "varchar"`,
+		},
+		{
+			name: "unknown annotation",
+			schema: `message root {
+  required binary name (TEXT);
+}`,
+			err: "line 2: invalid annotation TEXT of name, err: unknown annotation",
+		},
+		{
+			name: "repeated list",
+			schema: `message root {
+  repeated group tags (LIST) {
+    repeated binary element;
+  }
+}`,
+			err: "line 2: LIST group tags can't be repeated",
+		},
+		{
+			name: "map without key",
+			schema: `message root {
+  optional group attrs (MAP) {
+    repeated group key_value {
+      optional binary key;
+    }
+  }
+}`,
+			err: "line 2: MAP group attrs must have a repeated group with a required key and an optional value",
+		},
+		{
+			name:   "empty group",
+			schema: "message root {\n  optional group address {\n  }\n}",
+			err:    "line 3: group address has no fields",
+		},
+		{
+			name:   "missing semicolon",
+			schema: "message root {\n  required int64 id\n}",
+			err:    `line 3: expected ";", found "}"`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%02d %s", i, tc.name), func(t *testing.T) {
+			schema, err := message.Parse(strings.NewReader(tc.schema))
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, schema)
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	in := `message root {
+  required int64 id = 1; // max def 0, max rep 0
+  repeated group friends {
+    required binary name (STRING); // max def 1, max rep 1
+    optional group hobby {
+      optional int32 difficulty (INTEGER(8,true)); // max def 3, max rep 1
+    }
+  }
+  optional group tags (LIST) {
+    repeated group list {
+      required binary element; // max def 2, max rep 1
+    }
+  }
+}
+`
+	schema, err := message.Parse(strings.NewReader(in))
+	require.NoError(t, err)
+
+	out, err := message.Format(schema)
+	require.NoError(t, err)
+	assert.Equal(t, in, out)
+}
@@ -208,6 +208,32 @@ func TestFields(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "field ids",
+			typ:  "TaggedIDs",
+			expected: fields.Field{
+				Children: []fields.Field{
+					{Type: "int32", Name: "ID", ColumnName: "id", ID: 1, RepetitionType: fields.Required},
+					{Type: "string", Name: "Name", ColumnName: "Name", ID: 2, RepetitionType: fields.Optional},
+				},
+			},
+		},
+		{
+			name: "logical types",
+			typ:  "Logical",
+			expected: fields.Field{
+				Children: []fields.Field{
+					{Type: "int32", Name: "Total", ColumnName: "total", ID: 1, Annotation: "DECIMAL(9,2)", RepetitionType: fields.Required},
+					{Type: "int32", Name: "Due", ColumnName: "due", Annotation: "DATE", RepetitionType: fields.Optional},
+					{Type: "string", Name: "Name", ColumnName: "name", Annotation: "STRING", RepetitionType: fields.Required},
+					{Type: "Labels", Name: "Tags", ColumnName: "tags", Annotation: "LIST", RepetitionType: fields.Optional, Children: []fields.Field{
+						{Type: "Label", Name: "List", ColumnName: "list", RepetitionType: fields.Repeated, Children: []fields.Field{
+							{Type: "string", Name: "Element", ColumnName: "element", Annotation: "STRING", RepetitionType: fields.Required},
+						}},
+					}},
+				},
+			},
+		},
 		{
 			name: "omit tag",
 			typ:  "IgnoreMe",
@@ -5,10 +5,13 @@ import (
 	"go/parser"
 	"go/token"
 	"log"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"go/ast"
 
+	"github.com/parsyl/parquet"
 	"github.com/parsyl/parquet/cmd/parquetgen/fields"
 	flds "github.com/parsyl/parquet/cmd/parquetgen/fields"
 )
@@ -102,6 +105,8 @@ func getChildren(parent *flds.Field, fields map[string]flds.Field) []error {
 		f.Name = child.Name
 		f.Type = child.Type
 		f.ColumnName = child.ColumnName
+		f.ID = child.ID
+		f.Annotation = child.Annotation
 		f.Children = child.Children
 		f.RepetitionType = child.RepetitionType
 
@@ -129,6 +134,7 @@ func isPrivate(x *ast.Field) bool {
 
 func getFields(n map[string]ast.Node) (map[string]fields.Field, error) {
 	fields := map[string]flds.Field{}
+	var err error
 	for k, n := range n {
 		_, ok := n.(*ast.TypeSpec)
 		if !ok {
@@ -147,14 +153,18 @@ func getFields(n map[string]ast.Node) (map[string]fields.Field, error) {
 			switch x := n.(type) {
 			case *ast.Field:
 				if len(x.Names) == 1 && !isPrivate(x) {
-					f, skip := getField(x.Names[0].Name, x, nil)
-					if !skip {
+					f, skip, e := getField(x.Names[0].Name, x, nil)
+					if e != nil {
+						err = e
+					} else if !skip {
 						parent.Children = append(parent.Children, f)
 					}
 				} else if len(x.Names) == 0 && !isPrivate(x) {
-					f, skip := getField(fmt.Sprintf("%s", x.Type), x, nil)
+					f, skip, e := getField(fmt.Sprintf("%s", x.Type), x, nil)
 					f.Embedded = true
-					if !skip {
+					if e != nil {
+						err = e
+					} else if !skip {
 						parent.Children = append(parent.Children, f)
 					}
 				}
@@ -165,7 +175,7 @@ func getFields(n map[string]ast.Node) (map[string]fields.Field, error) {
 		fields[k] = parent
 	}
 
-	return fields, nil
+	return fields, err
 }
 
 func getType(typ string) string {
@@ -173,14 +183,19 @@ func getType(typ string) string {
 	return parts[len(parts)-1]
 }
 
-func getField(name string, x ast.Node, parent *flds.Field) (flds.Field, bool) {
-	var typ, tag string
+func getField(name string, x ast.Node, parent *flds.Field) (flds.Field, bool, error) {
+	var typ string
+	var tag parquet.Tag
+	var err error
 	var optional, repeated bool
 	ast.Inspect(x, func(n ast.Node) bool {
 		switch t := n.(type) {
 		case *ast.Field:
 			if t.Tag != nil {
-				tag = parseTag(t.Tag.Value)
+				tag, err = parseTag(t.Tag.Value)
+				if err != nil {
+					err = fmt.Errorf("invalid tag on field %s, err: %s", name, err)
+				}
 			}
 			typ = fmt.Sprintf("%s", t.Type)
 		case *ast.ArrayType:
@@ -201,8 +216,8 @@ func getField(name string, x ast.Node, parent *flds.Field) (flds.Field, bool) {
 		return true
 	})
 
-	if tag == "" {
-		tag = name
+	if tag.Name == "" {
+		tag.Name = name
 	}
 
 	rt := fields.Required
@@ -215,18 +230,21 @@ func getField(name string, x ast.Node, parent *flds.Field) (flds.Field, bool) {
 	return flds.Field{
 		Type:           typ,
 		Name:           name,
-		ColumnName:     tag,
+		ColumnName:     tag.Name,
 		RepetitionType: rt,
-	}, tag == "-"
+		ID:             tag.ID,
+		Annotation:     tag.Logical,
+	}, tag.Name == "-", err
 }
 
-func parseTag(t string) string {
-	i := strings.Index(t, `parquet:"`)
-	if i == -1 {
-		return ""
+// parseTag parses the parquet key of a struct tag
+// (the literal of the tag, with its quotes).
+func parseTag(t string) (parquet.Tag, error) {
+	s, err := strconv.Unquote(t)
+	if err != nil {
+		return parquet.Tag{}, err
 	}
-	t = t[i+9:]
-	return t[:strings.Index(t, `"`)]
+	return parquet.ParseTag(reflect.StructTag(s).Get("parquet"))
 }
 
 type visitorFunc func(n ast.Node) ast.Visitor
@@ -41,6 +41,26 @@ type Tagged struct {
 	Name string `parquet:"name"`
 }
 
+type TaggedIDs struct {
+	ID   int32   `parquet:"id,id=1"`
+	Name *string `parquet:",id=2"`
+}
+
+type Logical struct {
+	Total int32   `parquet:"total,id=1,logical=DECIMAL(9,2)"`
+	Due   *int32  `parquet:"due,logical=DATE"`
+	Name  string  `parquet:"name,logical=STRING"`
+	Tags  *Labels `parquet:"tags,logical=LIST"`
+}
+
+type Labels struct {
+	List []Label `parquet:"list"`
+}
+
+type Label struct {
+	Element string `parquet:"element,logical=STRING"`
+}
+
 type Private struct {
 	Being
 	name string
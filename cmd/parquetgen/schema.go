@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/parsyl/parquet"
+	"github.com/parsyl/parquet/cmd/parquetgen/message"
+)
+
+// schema is the schema subcommand, which prints the schema of a
+// parquet file in the parquet message syntax:
+//
+//	parquetgen schema file.parquet
+func schema(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: parquetgen schema file.parquet")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	footer, err := parquet.ReadMetaData(f)
+	if err != nil {
+		return fmt.Errorf("couldn't read footer: %s", err)
+	}
+
+	s, err := message.Format(footer.Schema)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Print(s)
+	return err
+}
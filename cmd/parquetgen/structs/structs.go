@@ -4,18 +4,22 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/parsyl/parquet/cmd/parquetgen/message"
 	sch "github.com/parsyl/parquet/schema"
 )
 
 // Struct generates a struct definition based on the
-// parquet schema.
+// parquet schema.  Optional fields are pointers and repeated
+// fields are slices.  A group is a struct named after the group
+// (or, if that name is taken, after its parent and the group).
 func Struct(structName string, schema []*sch.SchemaElement) string {
 	if len(schema) == 0 {
 		return ""
 	}
 
 	schema[0].Name = structName
-	_, out := getStruct(schema[0], schema[1:])
+	names := map[string]bool{strings.Title(structName): true}
+	_, out := getStruct(strings.Title(structName), schema[0], schema[1:], names)
 	if strings.Contains(out, "%s") {
 		out = fmt.Sprintf(out, "")
 	}
@@ -23,19 +27,22 @@ func Struct(structName string, schema []*sch.SchemaElement) string {
 	return out
 }
 
-func getStruct(parent *sch.SchemaElement, children []*sch.SchemaElement) (int, string) {
+func getStruct(typ string, parent *sch.SchemaElement, children []*sch.SchemaElement, names map[string]bool) (int, string) {
 	str := fmt.Sprintf(`type %s struct {
 	%%s
-}`, strings.Title(parent.Name))
+}`, typ)
 	var i, j int
 	var fields string
 	for i < int(*parent.NumChildren) {
 		ch := children[i+j]
-		fields = fmt.Sprintf("%s\n%s", fields, field(ch))
 		if ch.NumChildren != nil && int(*ch.NumChildren) > 0 {
-			n, s := getStruct(ch, children[i+j+1:])
+			t := typeName(typ, ch.Name, names)
+			fields = fmt.Sprintf("%s\n%s", fields, field(ch, t))
+			n, s := getStruct(t, ch, children[i+j+1:], names)
 			j += n
 			str += fmt.Sprintf("\n\n%s", s)
+		} else {
+			fields = fmt.Sprintf("%s\n%s", fields, field(ch, getType(ch)))
 		}
 		i++
 	}
@@ -43,21 +50,73 @@ func getStruct(parent *sch.SchemaElement, children []*sch.SchemaElement) (int, s
 	return i + j, fmt.Sprintf(str, fields)
 }
 
-func field(elem *sch.SchemaElement) string {
-	n := strings.Title(elem.Name)
-	t := n
-	if elem.Type != nil {
-		t = getType(elem.Type.String())
+// typeName returns a name for the struct of a group that
+// isn't already taken by another struct.
+func typeName(parent, name string, names map[string]bool) string {
+	out := strings.Title(name)
+	if names[out] {
+		out = parent + out
+	}
+
+	for i := 2; names[out]; i++ {
+		out = fmt.Sprintf("%s%s%d", parent, strings.Title(name), i)
 	}
+	names[out] = true
+	return out
+}
+
+func field(elem *sch.SchemaElement, t string) string {
+	n := strings.Title(elem.Name)
 	var ptr string
 	if elem.RepetitionType != nil && *elem.RepetitionType == sch.FieldRepetitionType_OPTIONAL {
 		ptr = "*"
+	} else if elem.RepetitionType != nil && *elem.RepetitionType == sch.FieldRepetitionType_REPEATED {
+		ptr = "[]"
+	}
+	tag := elem.Name
+	if elem.IsSetFieldID() && elem.GetFieldID() > 0 {
+		tag = fmt.Sprintf("%s,id=%d", tag, elem.GetFieldID())
+	}
+	if a := message.Annotation(elem); a != "" && !implied[t][a] {
+		tag = fmt.Sprintf("%s,logical=%s", tag, a)
 	}
-	return fmt.Sprintf("%s %s%s `parquet:\"%s\"`", n, ptr, t, elem.Name)
+	return fmt.Sprintf("%s %s%s `parquet:\"%s\"`", n, ptr, t, tag)
 }
 
-func getType(t string) string {
-	return parquetTypes[t]
+// implied are the annotations that the Go type of a column
+// already sets, so they don't have to be in its tag.
+var implied = map[string]map[string]bool{
+	"uint32": {"INTEGER(32,false)": true, "UINT_32": true},
+	"uint64": {"INTEGER(64,false)": true, "UINT_64": true},
+}
+
+// getType returns the Go type of a column.  Integers whose logical
+// (or converted) type is unsigned are uints and the types that aren't
+// supported are byte arrays, which parquetgen skips (see -ignore).
+func getType(elem *sch.SchemaElement) string {
+	switch elem.GetType() {
+	case sch.Type_INT32, sch.Type_INT64:
+		if unsigned(elem) {
+			return "u" + parquetTypes[elem.GetType().String()]
+		}
+	case sch.Type_INT96:
+		return "[12]byte"
+	case sch.Type_FIXED_LEN_BYTE_ARRAY:
+		return fmt.Sprintf("[%d]byte", elem.GetTypeLength())
+	}
+	return parquetTypes[elem.GetType().String()]
+}
+
+func unsigned(elem *sch.SchemaElement) bool {
+	if lt := elem.GetLogicalType(); lt != nil && lt.IsSetINTEGER() {
+		return !lt.INTEGER.IsSigned
+	}
+
+	switch elem.GetConvertedType() {
+	case sch.ConvertedType_UINT_8, sch.ConvertedType_UINT_16, sch.ConvertedType_UINT_32, sch.ConvertedType_UINT_64:
+		return elem.IsSetConvertedType()
+	}
+	return false
 }
 
 var parquetTypes = map[string]string{
@@ -26,6 +26,26 @@ func TestStruct(t *testing.T) {
 			},
 			expected: "type Root struct {\n	Id int32 `parquet:\"id\"`\n}",
 		},
+		{
+			name: "field id",
+			schema: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(1)},
+				{Name: "id", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), FieldID: pint32(7)},
+			},
+			expected: "type Root struct {\n	Id int32 `parquet:\"id,id=7\"`\n}",
+		},
+		{
+			name: "logical types",
+			schema: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(5)},
+				{Name: "price", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{DECIMAL: &sch.DecimalType{Precision: 9, Scale: 2}}, ConvertedType: pct(sch.ConvertedType_DECIMAL), Precision: pint32(9), Scale: pint32(2)},
+				{Name: "day", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), LogicalType: &sch.LogicalType{DATE: &sch.DateType{}}, ConvertedType: pct(sch.ConvertedType_DATE)},
+				{Name: "name", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), ConvertedType: pct(sch.ConvertedType_UTF8)},
+				{Name: "small", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{INTEGER: &sch.IntType{BitWidth: 8, IsSigned: true}}},
+				{Name: "n", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), LogicalType: &sch.LogicalType{INTEGER: &sch.IntType{BitWidth: 32}}},
+			},
+			expected: "type Root struct {\n	Price int32  `parquet:\"price,logical=DECIMAL(9,2)\"`\n	Day   *int32 `parquet:\"day,logical=DATE\"`\n	Name  string `parquet:\"name,logical=UTF8\"`\n	Small int32  `parquet:\"small,logical=INTEGER(8,true)\"`\n	N     uint32 `parquet:\"n\"`\n}",
+		},
 		{
 			name: "single nested field",
 			schema: []*sch.SchemaElement{
@@ -61,6 +81,30 @@ func TestStruct(t *testing.T) {
 			},
 			expected: "type Root struct {\n	Hobby Hobby  `parquet:\"hobby\"`\n	Id    *int32 `parquet:\"id\"`\n}\n\ntype Hobby struct {\n	Name       *Name `parquet:\"name\"`\n	Difficulty int32 `parquet:\"difficulty\"`\n}\n\ntype Name struct {\n	First *string `parquet:\"first\"`\n	Last  string  `parquet:\"last\"`\n}",
 		},
+		{
+			name: "repeated and unsigned",
+			schema: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(3)},
+				{Name: "ids", Type: pt(sch.Type_INT64), RepetitionType: prt(sch.FieldRepetitionType_REPEATED), LogicalType: &sch.LogicalType{INTEGER: &sch.IntType{BitWidth: 64}}},
+				{Name: "count", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), ConvertedType: pct(sch.ConvertedType_UINT_32)},
+				{Name: "friends", RepetitionType: prt(sch.FieldRepetitionType_REPEATED), NumChildren: pint32(1)},
+				{Name: "name", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED)},
+			},
+			expected: "type Root struct {\n	Ids     []uint64  `parquet:\"ids\"`\n	Count   *uint32   `parquet:\"count\"`\n	Friends []Friends `parquet:\"friends\"`\n}\n\ntype Friends struct {\n	Name string `parquet:\"name\"`\n}",
+		},
+		{
+			name: "same group name twice",
+			schema: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(2)},
+				{Name: "tags", RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), NumChildren: pint32(1), LogicalType: &sch.LogicalType{LIST: &sch.ListType{}}},
+				{Name: "list", RepetitionType: prt(sch.FieldRepetitionType_REPEATED), NumChildren: pint32(1)},
+				{Name: "element", Type: pt(sch.Type_BYTE_ARRAY), RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL)},
+				{Name: "scores", RepetitionType: prt(sch.FieldRepetitionType_OPTIONAL), NumChildren: pint32(1), LogicalType: &sch.LogicalType{LIST: &sch.ListType{}}},
+				{Name: "list", RepetitionType: prt(sch.FieldRepetitionType_REPEATED), NumChildren: pint32(1)},
+				{Name: "element", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED)},
+			},
+			expected: "type Root struct {\n	Tags   *Tags   `parquet:\"tags,logical=LIST\"`\n	Scores *Scores `parquet:\"scores,logical=LIST\"`\n}\n\ntype Tags struct {\n	List []List `parquet:\"list\"`\n}\n\ntype List struct {\n	Element *string `parquet:\"element\"`\n}\n\ntype Scores struct {\n	List []ScoresList `parquet:\"list\"`\n}\n\ntype ScoresList struct {\n	Element int32 `parquet:\"element\"`\n}",
+		},
 	}
 
 	for i, tc := range testCases {
@@ -86,3 +130,7 @@ func prt(rt sch.FieldRepetitionType) *sch.FieldRepetitionType {
 func pt(t sch.Type) *sch.Type {
 	return &t
 }
+
+func pct(t sch.ConvertedType) *sch.ConvertedType {
+	return &t
+}
@@ -0,0 +1,360 @@
+package parquet
+
+import (
+	"io"
+	"reflect"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// Value is the Go type of the values of a column
+type Value interface {
+	int32 | uint32 | int64 | uint64 | float32 | float64 | bool | string
+}
+
+// Codec creates the columns of T, which add the values of each record
+// to the current page (Column.Add) and set the fields of a record to the
+// values that were read (Column.Scan).  parquetgen generates a Codec for
+// a struct so that it can be written and read by a Writer and a Reader
+// without reflection.
+type Codec[T any] interface {
+	// Fields creates the columns of T with the compression c
+	Fields(c sch.CompressionCodec) []Column[T]
+}
+
+// RequiredFieldCompression sets the compression of a column.
+// It is an optional arg to NewRequiredField.
+func RequiredFieldCompression(c sch.CompressionCodec) func(*RequiredField) {
+	return func(r *RequiredField) {
+		r.compression = c
+	}
+}
+
+// OptionalFieldCompression sets the compression of a column.
+// It is an optional arg to NewOptionalField.
+func OptionalFieldCompression(c sch.CompressionCodec) func(*OptionalField) {
+	return func(o *OptionalField) {
+		o.compression = c
+	}
+}
+
+// valueFuncs are the parts of a column that depend on the type of its values
+type valueFuncs[V Value] struct {
+	typ FieldFunc
+	// width is the encoded size of each value unless size is set
+	width int
+	size  func(V) int
+	// check is nil if there aren't any checks for V
+	check func(c Checks, col string, v V) error
+}
+
+func funcsOf[V Value]() valueFuncs[V] {
+	var v V
+	out := valueFuncs[V]{typ: kinds[reflect.TypeOf(v).Kind()].typ}
+	switch any(v).(type) {
+	case int32, uint32:
+		out.width = 4
+	case int64, uint64:
+		out.width = 8
+	case float32:
+		out.width = 4
+		out.check = any(func(c Checks, col string, f float32) error { return c.Float(col, float64(f)) }).(func(Checks, string, V) error)
+	case float64:
+		out.width = 8
+		out.check = any(func(c Checks, col string, f float64) error { return c.Float(col, f) }).(func(Checks, string, V) error)
+	case string:
+		out.size = any(func(s string) int { return 4 + len(s) }).(func(V) int)
+		out.check = any(func(c Checks, col string, s string) error { return c.String(col, s) }).(func(Checks, string, V) error)
+	}
+	return out
+}
+
+// valuesSize is the number of bytes that vals take up once encoded.
+// n is the size of the values if they have a size func.
+func (f valueFuncs[V]) valuesSize(vals []V, n int) int {
+	if f.size != nil {
+		return n
+	}
+
+	if f.width == 0 {
+		// bools are bit packed
+		return (len(vals) + 7) / 8
+	}
+	return len(vals) * f.width
+}
+
+// RequiredColumn is a column of T that isn't optional or repeated and
+// doesn't belong to an optional or repeated group.  read returns the
+// value of a record and write sets the value of a record to vals[0].
+type RequiredColumn[T any, V Value] struct {
+	RequiredField
+	vals  []V
+	size  int
+	funcs valueFuncs[V]
+	read  func(r T) V
+	write func(r *T, vals []V)
+}
+
+// NewRequiredColumn creates a RequiredColumn
+func NewRequiredColumn[T any, V Value](read func(r T) V, write func(r *T, vals []V), path []string, opts ...func(*RequiredField)) *RequiredColumn[T, V] {
+	return &RequiredColumn[T, V]{
+		RequiredField: NewRequiredField(path, opts...),
+		funcs:         funcsOf[V](),
+		read:          read,
+		write:         write,
+	}
+}
+
+func (f *RequiredColumn[T, V]) Schema() Field {
+	return Field{Name: f.Name(), Path: f.Path(), Type: f.funcs.typ, RepetitionType: RepetitionRequired, Types: []int{0}, ID: f.ID(), Annotations: f.Annotations()}
+}
+
+func (f *RequiredColumn[T, V]) Read(r io.ReadSeeker, pg Page) error {
+	rr, sizes, err := f.DoRead(r, pg)
+	if err != nil {
+		return err
+	}
+
+	v, err := decodeValues[V](rr, pg.Type, pg.N, sizes)
+	f.vals = append(f.vals, v...)
+	return err
+}
+
+func (f *RequiredColumn[T, V]) ReadPage(r io.ReadSeeker, pg *Page) error {
+	if len(f.vals) > 0 || pg.Done() {
+		return nil
+	}
+
+	rr, n, err := f.DoReadPage(r, pg)
+	if err != nil {
+		return err
+	}
+
+	v, err := decodeValues[V](rr, pg.Type, n, []int{n})
+	f.vals = append(f.vals, v...)
+	return err
+}
+
+func (f *RequiredColumn[T, V]) Write(w io.Writer, meta *Metadata) error {
+	buf := buffpool.Get()
+	defer buffpool.Put(buf)
+
+	vals := valuesOf(f.vals)
+	if err := vals.encode(buf); err != nil {
+		return err
+	}
+	return f.DoWrite(w, meta, buf.Bytes(), len(f.vals), vals.stats(nil, 0))
+}
+
+func (f *RequiredColumn[T, V]) Scan(r *T) {
+	if len(f.vals) == 0 {
+		return
+	}
+
+	f.write(r, f.vals)
+	f.vals = f.vals[1:]
+}
+
+func (f *RequiredColumn[T, V]) scanBatch(dst []T) {
+	n := min(len(dst), len(f.vals))
+	for i := range dst[:n] {
+		f.write(&dst[i], f.vals[i:])
+	}
+	f.vals = f.vals[n:]
+}
+
+func (f *RequiredColumn[T, V]) Add(r T) {
+	v := f.read(r)
+	f.vals = append(f.vals, v)
+	if f.funcs.size != nil {
+		f.size += f.funcs.size(v)
+	}
+}
+
+func (f *RequiredColumn[T, V]) Validate(r T, c Checks) error {
+	if f.funcs.check == nil {
+		return nil
+	}
+	return f.funcs.check(c, f.Name(), f.read(r))
+}
+
+func (f *RequiredColumn[T, V]) Size() int {
+	return f.funcs.valuesSize(f.vals, f.size)
+}
+
+func (f *RequiredColumn[T, V]) Skip(n int) int {
+	n = min(n, len(f.vals))
+	f.vals = f.vals[n:]
+	return n
+}
+
+func (f *RequiredColumn[T, V]) Levels() ([]uint8, []uint8) {
+	return nil, nil
+}
+
+func (f *RequiredColumn[T, V]) buffer() (any, []uint8, []uint8) {
+	return f.vals, nil, nil
+}
+
+// OptionalColumn is a column of T that is optional or repeated or that
+// belongs to an optional or repeated group.  read appends the values and
+// levels of a record and write sets the fields of a record to the values
+// and levels at the start of vals, defs and reps and returns how many of
+// the values and levels it used.
+type OptionalColumn[T any, V Value] struct {
+	OptionalField
+	vals  []V
+	size  int
+	funcs valueFuncs[V]
+	read  func(r T, vals []V, defs, reps []uint8) ([]V, []uint8, []uint8)
+	write func(r *T, vals []V, defs, reps []uint8) (int, int)
+}
+
+// NewOptionalColumn creates an OptionalColumn.  types is the
+// repetition type of each level of the column's path.
+func NewOptionalColumn[T any, V Value](read func(r T, vals []V, defs, reps []uint8) ([]V, []uint8, []uint8), write func(r *T, vals []V, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*OptionalField)) *OptionalColumn[T, V] {
+	return &OptionalColumn[T, V]{
+		OptionalField: NewOptionalField(path, types, opts...),
+		funcs:         funcsOf[V](),
+		read:          read,
+		write:         write,
+	}
+}
+
+func (f *OptionalColumn[T, V]) Schema() Field {
+	return Field{Name: f.Name(), Path: f.Path(), Type: f.funcs.typ, RepetitionType: f.RepetitionType, Types: f.Types, ID: f.ID(), Annotations: f.Annotations()}
+}
+
+func (f *OptionalColumn[T, V]) Write(w io.Writer, meta *Metadata) error {
+	buf := buffpool.Get()
+	defer buffpool.Put(buf)
+
+	vals := valuesOf(f.vals)
+	if err := vals.encode(buf); err != nil {
+		return err
+	}
+	return f.DoWrite(w, meta, buf.Bytes(), len(f.Defs), vals.stats(f.Defs, f.MaxLevels.Def))
+}
+
+func (f *OptionalColumn[T, V]) Read(r io.ReadSeeker, pg Page) error {
+	rr, sizes, err := f.DoRead(r, pg)
+	if err != nil {
+		return err
+	}
+
+	v, err := decodeValues[V](rr, pg.Type, f.Values()-len(f.vals), sizes)
+	f.vals = append(f.vals, v...)
+	return err
+}
+
+func (f *OptionalColumn[T, V]) ReadPage(r io.ReadSeeker, pg *Page) error {
+	for !f.Buffered() && !pg.Done() {
+		rr, n, err := f.DoReadPage(r, pg)
+		if err != nil {
+			return err
+		}
+
+		v, err := decodeValues[V](rr, pg.Type, n, []int{n})
+		if err != nil {
+			return err
+		}
+		f.vals = append(f.vals, v...)
+	}
+	return nil
+}
+
+func (f *OptionalColumn[T, V]) Add(r T) {
+	vals, defs, reps := f.read(r, f.vals, f.Defs, f.Reps)
+	if f.funcs.size != nil {
+		for _, v := range vals[len(f.vals):] {
+			f.size += f.funcs.size(v)
+		}
+	}
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
+func (f *OptionalColumn[T, V]) Scan(r *T) {
+	if len(f.Defs) == 0 {
+		return
+	}
+
+	v, l := f.write(r, f.vals, f.Defs, f.Reps)
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+}
+
+func (f *OptionalColumn[T, V]) scanBatch(dst []T) {
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for i := range dst {
+		if len(defs) == 0 {
+			break
+		}
+
+		v, l := f.write(&dst[i], vals, defs, reps)
+		vals = vals[v:]
+		defs = defs[l:]
+		if len(reps) > 0 {
+			reps = reps[l:]
+		}
+	}
+	f.vals, f.Defs, f.Reps = vals, defs, reps
+}
+
+func (f *OptionalColumn[T, V]) Validate(r T, c Checks) error {
+	if f.funcs.check == nil {
+		return nil
+	}
+
+	vals, _, _ := f.read(r, nil, nil, nil)
+	for _, v := range vals {
+		if err := f.funcs.check(c, f.Name(), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *OptionalColumn[T, V]) Size() int {
+	return f.funcs.valuesSize(f.vals, f.size) + f.LevelsSize()
+}
+
+func (f *OptionalColumn[T, V]) Skip(n int) int {
+	n, v := f.OptionalField.Skip(n)
+	f.vals = f.vals[v:]
+	return n
+}
+
+func (f *OptionalColumn[T, V]) Levels() ([]uint8, []uint8) {
+	return f.Defs, f.Reps
+}
+
+func (f *OptionalColumn[T, V]) buffer() (any, []uint8, []uint8) {
+	return f.vals, f.Defs, f.Reps
+}
+
+// Ptr returns a pointer to v.  The code generated by parquetgen
+// uses it to set optional fields.
+func Ptr[V any](v V) *V {
+	return &v
+}
+
+// Indices keeps track of the index of each repeated level of a column
+// while the code generated by parquetgen sets the fields of a record.
+type Indices []int
+
+// Rep moves to the next element of the repeated level rep (the
+// levels below it start over).  A rep of 0 doesn't change anything.
+func (i Indices) Rep(rep uint8) {
+	if rep > 0 {
+		r := int(rep) - 1
+		i[r] = i[r] + 1
+		for j := int(rep); j < len(i); j++ {
+			i[j] = 0
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package parquet
+
+import (
+	"strings"
+)
+
+// SetCaseInsensitive makes ReadFooter match the columns of the file
+// to the columns of m's schema without regard to case when they don't
+// match by field_id or by name.  It has to be called before ReadFooter.
+func (m *Metadata) SetCaseInsensitive(b bool) {
+	m.caseInsensitive = b
+}
+
+// Column returns the name of the column of m's schema that the column
+// of the file with the given path is read into.  It is false if the
+// column isn't in m's schema.  A column of the schema that has an ID is
+// matched to the column of the file with the same field_id, the rest of
+// the columns are matched by their full path and finally (see
+// SetCaseInsensitive) by their full path without regard to case.
+func (m *Metadata) Column(pth []string) (string, bool) {
+	col, ok := m.columns[strings.Join(pth, ".")]
+	return col, ok
+}
+
+// resolveColumns maps the leaf columns of the file that was read by
+// ReadFooter to the columns of m's schema.  Each column is only matched
+// once.
+func (m *Metadata) resolveColumns() map[string]string {
+	names, actual := leaves(m.metadata.Schema)
+	out := map[string]string{}
+	matched := map[string]bool{}
+
+	match := func(f func(expected Field, col string, l leaf) bool) {
+		for _, fld := range m.schema.fields {
+			expected := strings.Join(fld.Path, ".")
+			if matched[expected] {
+				continue
+			}
+
+			for _, col := range names {
+				if _, ok := out[col]; ok || !f(fld, col, actual[col]) {
+					continue
+				}
+
+				out[col] = expected
+				matched[expected] = true
+				break
+			}
+		}
+	}
+
+	match(func(expected Field, _ string, l leaf) bool {
+		return expected.ID != 0 && l.se.IsSetFieldID() && l.se.GetFieldID() == expected.ID
+	})
+
+	match(func(expected Field, col string, _ leaf) bool {
+		return strings.Join(expected.Path, ".") == col
+	})
+
+	if m.caseInsensitive {
+		match(func(expected Field, col string, _ leaf) bool {
+			return strings.EqualFold(strings.Join(expected.Path, "."), col)
+		})
+	}
+
+	return out
+}
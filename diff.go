@@ -0,0 +1,288 @@
+package parquet
+
+import (
+	"fmt"
+	"strings"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// SchemaDiffKind is the kind of a SchemaDiff
+type SchemaDiffKind int
+
+const (
+	// MissingColumn is a column of the struct that isn't in the file.
+	// Scan doesn't set its field.
+	MissingColumn SchemaDiffKind = iota
+	// UnknownColumn is a column of the file that isn't in the struct.
+	// It isn't read.
+	UnknownColumn
+	// WidenedColumn is a column that is read into a wider type
+	// (INT32 into int64 or uint64 and FLOAT into float64).
+	WidenedColumn
+	// TypeMismatch is a column with a type that can't be read into its field
+	TypeMismatch
+	// LogicalTypeMismatch is a column with a logical type that changes
+	// how its values have to be read (for example a signed column that
+	// is read into an unsigned field or a decimal).
+	LogicalTypeMismatch
+	// RepetitionMismatch is a column that is required, optional or
+	// repeated at a different level of its path than the field.
+	RepetitionMismatch
+)
+
+// SchemaDiff is a difference between the schema of
+// a file and the schema of the struct it is read into.
+type SchemaDiff struct {
+	Kind     SchemaDiffKind
+	Column   string
+	Expected string
+	Actual   string
+}
+
+func (d SchemaDiff) String() string {
+	switch d.Kind {
+	case MissingColumn:
+		return fmt.Sprintf("column %s is missing from the file", d.Column)
+	case UnknownColumn:
+		return fmt.Sprintf("column %s is not in the struct", d.Column)
+	case WidenedColumn:
+		return fmt.Sprintf("column %s is %s in the file and is widened to %s", d.Column, d.Actual, d.Expected)
+	case LogicalTypeMismatch:
+		return fmt.Sprintf("column %s has logical type %s in the file, expected %s", d.Column, d.Actual, d.Expected)
+	case RepetitionMismatch:
+		return fmt.Sprintf("column %s has repetition %s in the file, expected %s", d.Column, d.Actual, d.Expected)
+	default:
+		return fmt.Sprintf("column %s is %s in the file, expected %s", d.Column, d.Actual, d.Expected)
+	}
+}
+
+// Safe is true if the difference doesn't stop the file from being read
+func (d SchemaDiff) Safe() bool {
+	return d.Kind == MissingColumn || d.Kind == UnknownColumn || d.Kind == WidenedColumn
+}
+
+// SchemaError lists the differences between the schema
+// of a file and the schema of the struct it is read into.
+type SchemaError struct {
+	Diffs []SchemaDiff
+}
+
+func (e *SchemaError) Error() string {
+	out := make([]string, len(e.Diffs))
+	for i, d := range e.Diffs {
+		out[i] = d.String()
+	}
+	return fmt.Sprintf("schema doesn't match: %s", strings.Join(out, "; "))
+}
+
+// SchemaDiffs compares the schema of the file that was read by
+// ReadFooter to the schema that m was created with (see Column for
+// how the columns are matched).  The differences are in the order of
+// the struct's columns followed by the columns that are only in the file.
+func (m *Metadata) SchemaDiffs() []SchemaDiff {
+	if m.metadata == nil {
+		return nil
+	}
+
+	names, actual := leaves(m.metadata.Schema)
+	cols := make(map[string]string, len(m.columns))
+	for k, v := range m.columns {
+		cols[v] = k
+	}
+
+	var out []SchemaDiff
+	for _, f := range m.schema.fields {
+		col := strings.Join(f.Path, ".")
+		expected := m.schema.lookup[col]
+		a, ok := actual[cols[col]]
+		if !ok {
+			out = append(out, SchemaDiff{Kind: MissingColumn, Column: col})
+			continue
+		}
+
+		if e, r := repetitions(f.Types), repetitions(a.reps); e != r {
+			out = append(out, SchemaDiff{Kind: RepetitionMismatch, Column: col, Expected: e, Actual: r})
+		}
+
+		if e, l := logicalType(&expected), logicalType(a.se); e != l {
+			out = append(out, SchemaDiff{Kind: LogicalTypeMismatch, Column: col, Expected: e, Actual: l})
+		}
+
+		if expected.GetType() == a.se.GetType() {
+			continue
+		}
+
+		d := SchemaDiff{Kind: TypeMismatch, Column: col, Expected: expected.GetType().String(), Actual: a.se.GetType().String()}
+		if widens(a.se.GetType(), expected.GetType()) {
+			d.Kind = WidenedColumn
+		}
+		out = append(out, d)
+	}
+
+	for _, col := range names {
+		if _, ok := m.columns[col]; !ok {
+			out = append(out, SchemaDiff{Kind: UnknownColumn, Column: col})
+		}
+	}
+	return out
+}
+
+// widens is true if a column of type from can be read into a field of type to
+func widens(from, to sch.Type) bool {
+	return (from == sch.Type_INT32 && to == sch.Type_INT64) ||
+		(from == sch.Type_FLOAT && to == sch.Type_DOUBLE)
+}
+
+// logicalType describes the logical type of a column in the terms that
+// matter when it is read: whether it is unsigned or a decimal.
+func logicalType(se *sch.SchemaElement) string {
+	if se.IsSetLogicalType() {
+		lt := se.LogicalType
+		switch {
+		case lt.IsSetINTEGER() && !lt.INTEGER.IsSigned:
+			return "UNSIGNED"
+		case lt.IsSetDECIMAL():
+			return "DECIMAL"
+		}
+	}
+
+	if se.IsSetConvertedType() {
+		switch *se.ConvertedType {
+		case sch.ConvertedType_UINT_8, sch.ConvertedType_UINT_16, sch.ConvertedType_UINT_32, sch.ConvertedType_UINT_64:
+			return "UNSIGNED"
+		case sch.ConvertedType_DECIMAL:
+			return "DECIMAL"
+		}
+	}
+	return "NONE"
+}
+
+// repetitions describes the repetition type of each level of a column's path
+func repetitions(types []int) string {
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = sch.FieldRepetitionType(t).String()
+	}
+	return strings.Join(out, ".")
+}
+
+type leaf struct {
+	se   *sch.SchemaElement
+	reps []int
+
+	// elems are the elements of each level of the column's path
+	elems []*sch.SchemaElement
+}
+
+// leaves returns the leaf columns (in order) of a file's schema along
+// with the repetition type of each level of their paths.
+func leaves(elems []*sch.SchemaElement) ([]string, map[string]leaf) {
+	names, m, _, _ := walkSchema(elems)
+	return names, m
+}
+
+// NormalizeSchema returns a copy of the schema elements of a file's
+// footer in which the num_children of each group is the number of its
+// direct children.  It only changes the schemas of files that were
+// written by earlier versions of this package, which counted every leaf
+// below a group as its num_children.
+func NormalizeSchema(elems []*sch.SchemaElement) []*sch.SchemaElement {
+	_, _, children, ok := walkSchema(elems)
+	if !ok {
+		return elems
+	}
+
+	out := make([]*sch.SchemaElement, len(elems))
+	for i, se := range elems {
+		e := *se
+		if se.GetNumChildren() > 0 {
+			n := int32(children[i])
+			e.NumChildren = &n
+		}
+		out[i] = &e
+	}
+	return out
+}
+
+// walkSchema walks the schema tree with the num_children of the file
+// or, if they don't add up, with the legacy num_children (see walkLeaves).
+// It is false if neither of them add up.
+func walkSchema(elems []*sch.SchemaElement) ([]string, map[string]leaf, []int, bool) {
+	names, m, children, ok := walkLeaves(elems, false)
+	if !ok {
+		// Files written by earlier versions of this package counted every
+		// leaf below a group (instead of its direct children) as the group's
+		// num_children and also counted nested groups as children of the root.
+		names, m, children, ok = walkLeaves(elems, true)
+	}
+	return names, m, children, ok
+}
+
+// walkLeaves walks the schema tree.  If legacy is set the num_children of
+// a group is the number of leaves below it and the root's num_children is
+// the number of its leaves plus the number of groups.  It also returns the number of direct children of each element.
+// It is false if the tree doesn't have the number of elements that
+// num_children says it has.
+func walkLeaves(elems []*sch.SchemaElement, legacy bool) ([]string, map[string]leaf, []int, bool) {
+	var names []string
+	m := map[string]leaf{}
+	children := make([]int, len(elems))
+	if len(elems) == 0 {
+		return names, m, children, true
+	}
+
+	ok := true
+	var i, groups, rootLeaves int
+	var walk func(pth []string, reps []int, ses []*sch.SchemaElement) int
+	walk = func(pth []string, reps []int, ses []*sch.SchemaElement) int {
+		idx := i
+		se := elems[i]
+		root := i == 0
+		i++
+		if !root {
+			pth = append(pth[:len(pth):len(pth)], se.Name)
+			reps = append(reps[:len(reps):len(reps)], int(se.GetRepetitionType()))
+			ses = append(ses[:len(ses):len(ses)], se)
+		}
+
+		if se.GetNumChildren() == 0 {
+			col := strings.Join(pth, ".")
+			names = append(names, col)
+			m[col] = leaf{se: se, reps: reps, elems: ses}
+			return 1
+		}
+
+		if !root {
+			groups++
+		}
+
+		done := func(j, n int) bool {
+			if legacy {
+				return !root && n >= int(se.GetNumChildren())
+			}
+			return j >= int(se.GetNumChildren())
+		}
+
+		var n int
+		for j := 0; !done(j, n); j++ {
+			if i >= len(elems) {
+				// the root of a legacy schema is read until the end
+				ok = ok && legacy && root
+				return n
+			}
+			if root && elems[i].GetNumChildren() == 0 {
+				rootLeaves++
+			}
+			n += walk(pth, reps, ses)
+			children[idx]++
+		}
+		return n
+	}
+
+	walk(nil, nil, nil)
+	if legacy {
+		ok = ok && int(elems[0].GetNumChildren()) == rootLeaves+groups
+	}
+	return names, m, children, ok && i == len(elems)
+}
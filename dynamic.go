@@ -0,0 +1,307 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// DynamicReader reads the rows of a parquet file whose schema isn't known
+// until the file is opened.  Each row is a Row: groups are nested
+// map[string]any values, repeated fields are []any and nulls are nil.
+type DynamicReader struct {
+	footer *sch.FileMetaData
+	schema *Schema
+	names  []string
+
+	// fields holds the columns of the current row group
+	fields []Column[Row]
+
+	r              io.ReadSeeker
+	rows           int64
+	cursor         int64
+	rowGroupCursor int64
+	rowGroupCount  int64
+
+	// rowGroup is the index of the next row group to be read
+	rowGroup int
+
+	row Row
+	err error
+}
+
+// OpenDynamic creates a DynamicReader for the parquet file in r.  The
+// columns are found by walking the schema in the file's footer, so
+// no struct (or generated code) is needed.
+func OpenDynamic(r io.ReadSeeker) (*DynamicReader, error) {
+	footer, err := ReadMetaData(r)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := SchemaOf(footer)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(s.leaves))
+	for i, l := range s.leaves {
+		names[i] = strings.Join(l.path, ".")
+	}
+
+	return &DynamicReader{
+		footer: footer,
+		schema: s,
+		names:  names,
+		r:      r,
+		rows:   footer.NumRows,
+	}, nil
+}
+
+// SchemaOf returns the Schema of a file's footer (see ReadMetaData).
+func SchemaOf(footer *sch.FileMetaData) (*Schema, error) {
+	names, lvs := leaves(footer.Schema)
+	var nodes []Node
+	for _, name := range names {
+		l := lvs[name]
+		kind, err := dynamicKind(l.se)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read column %s, err: %s", name, err)
+		}
+
+		nodes = addNode(nodes, l.elems, Node{kind: kind, id: l.se.GetFieldID()})
+	}
+	return newSchema(nodes), nil
+}
+
+// addNode adds the leaf n at the path of elems (the schema elements of
+// each level of the leaf's path) to nodes and returns the nodes.
+func addNode(nodes []Node, elems []*sch.SchemaElement, n Node) []Node {
+	se := elems[0]
+	if len(elems) == 1 {
+		n.name = se.Name
+		n.rep = RepetitionType(se.GetRepetitionType())
+		n.annotation = Annotation{LogicalType: se.LogicalType, ConvertedType: se.ConvertedType}
+		return append(nodes, n)
+	}
+
+	i := len(nodes) - 1
+	if i < 0 || nodes[i].name != se.Name || nodes[i].kind != reflect.Invalid {
+		nodes = append(nodes, Node{
+			name:       se.Name,
+			rep:        RepetitionType(se.GetRepetitionType()),
+			annotation: Annotation{LogicalType: se.LogicalType, ConvertedType: se.ConvertedType},
+		})
+		i++
+	}
+
+	nodes[i].children = addNode(nodes[i].children, elems[1:], n)
+	return nodes
+}
+
+// Schema returns the schema of the file
+func (d *DynamicReader) Schema() *Schema {
+	return d.schema
+}
+
+// Columns returns the names of the leaf columns of the file
+// (the names of nested columns are joined with a '.').
+func (d *DynamicReader) Columns() []string {
+	return d.names
+}
+
+// Rows is the number of rows in the file
+func (d *DynamicReader) Rows() int64 {
+	return d.rows
+}
+
+// Error returns the error that stopped Next
+func (d *DynamicReader) Error() error {
+	return d.err
+}
+
+// Next reads the next row.  It is false once all of the
+// rows have been read or if there was an error.
+func (d *DynamicReader) Next() bool {
+	if d.err != nil || d.cursor >= d.rows {
+		return false
+	}
+
+	for d.rowGroupCursor >= d.rowGroupCount {
+		if d.err = d.readRowGroup(); d.err != nil {
+			return false
+		}
+	}
+
+	d.row = Row{}
+	for _, f := range d.fields {
+		f.Scan(&d.row)
+	}
+
+	d.cursor++
+	d.rowGroupCursor++
+	return true
+}
+
+// Row returns the current row
+func (d *DynamicReader) Row() Row {
+	return d.row
+}
+
+// Map returns the current row as a map[string]any
+func (d *DynamicReader) Map() map[string]any {
+	return d.row
+}
+
+func (d *DynamicReader) readRowGroup() error {
+	if d.rowGroup >= len(d.footer.RowGroups) {
+		return fmt.Errorf("the row groups have %d rows, expected %d", d.cursor, d.rows)
+	}
+
+	rg := d.footer.RowGroups[d.rowGroup]
+	d.fields = d.schema.columns(sch.CompressionCodec_UNCOMPRESSED)
+	fields := make(map[string]Column[Row], len(d.fields))
+	for _, f := range d.fields {
+		fields[f.Name()] = f
+	}
+
+	for _, ch := range rg.Columns {
+		name := strings.Join(ch.MetaData.PathInSchema, ".")
+		f, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("column %s is not in the schema", name)
+		}
+
+		pg := Page{
+			N:      int(ch.MetaData.NumValues),
+			Offset: ch.FileOffset,
+			Size:   int(ch.MetaData.TotalCompressedSize),
+			Codec:  ch.MetaData.Codec,
+			Type:   ch.MetaData.Type,
+		}
+
+		if _, err := d.r.Seek(pg.Offset, io.SeekStart); err != nil {
+			return err
+		}
+
+		if err := f.Read(d.r, pg); err != nil {
+			return fmt.Errorf("unable to read column %s, err: %s", name, err)
+		}
+	}
+
+	d.rowGroup++
+	d.rowGroupCursor = 0
+	d.rowGroupCount = rg.NumRows
+	return nil
+}
+
+// dynamicKind returns the Go kind of the values of a column.
+func dynamicKind(se *sch.SchemaElement) (reflect.Kind, error) {
+	ct := se.GetConvertedType()
+	unsigned := se.IsSetConvertedType() && (ct == sch.ConvertedType_UINT_8 || ct == sch.ConvertedType_UINT_16 || ct == sch.ConvertedType_UINT_32 || ct == sch.ConvertedType_UINT_64)
+
+	switch se.GetType() {
+	case sch.Type_BOOLEAN:
+		return reflect.Bool, nil
+	case sch.Type_INT32:
+		if unsigned {
+			return reflect.Uint32, nil
+		}
+		return reflect.Int32, nil
+	case sch.Type_INT64:
+		if unsigned {
+			return reflect.Uint64, nil
+		}
+		return reflect.Int64, nil
+	case sch.Type_FLOAT:
+		return reflect.Float32, nil
+	case sch.Type_DOUBLE:
+		return reflect.Float64, nil
+	case sch.Type_BYTE_ARRAY:
+		return reflect.String, nil
+	}
+	return reflect.Invalid, fmt.Errorf("unsupported type %s", se.GetType())
+}
+
+// Row is a row that is read by a DynamicReader (or written by a
+// DynamicWriter).  The accessors take the
+// name of a field, which can be the path (joined by '.') of a field in a
+// nested group.  They are false if the field isn't in the row, is null or
+// has a different type.
+type Row map[string]any
+
+// Get returns the value of a field
+func (r Row) Get(pth string) (any, bool) {
+	m := map[string]any(r)
+	names := strings.Split(pth, ".")
+	for _, name := range names[:len(names)-1] {
+		g, ok := m[name].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		m = g
+	}
+
+	v, ok := m[names[len(names)-1]]
+	return v, ok && v != nil
+}
+
+// Group returns a nested group
+func (r Row) Group(pth string) (Row, bool) {
+	v, ok := getAs[map[string]any](r, pth)
+	return Row(v), ok
+}
+
+// List returns the elements of a repeated field
+func (r Row) List(pth string) ([]any, bool) {
+	return getAs[[]any](r, pth)
+}
+
+// Int32 returns the value of an INT32 column
+func (r Row) Int32(pth string) (int32, bool) {
+	return getAs[int32](r, pth)
+}
+
+// Uint32 returns the value of an INT32 column whose converted type is unsigned
+func (r Row) Uint32(pth string) (uint32, bool) {
+	return getAs[uint32](r, pth)
+}
+
+// Int64 returns the value of an INT64 column
+func (r Row) Int64(pth string) (int64, bool) {
+	return getAs[int64](r, pth)
+}
+
+// Uint64 returns the value of an INT64 column whose converted type is unsigned
+func (r Row) Uint64(pth string) (uint64, bool) {
+	return getAs[uint64](r, pth)
+}
+
+// Float32 returns the value of a FLOAT column
+func (r Row) Float32(pth string) (float32, bool) {
+	return getAs[float32](r, pth)
+}
+
+// Float64 returns the value of a DOUBLE column
+func (r Row) Float64(pth string) (float64, bool) {
+	return getAs[float64](r, pth)
+}
+
+// Bool returns the value of a BOOLEAN column
+func (r Row) Bool(pth string) (bool, bool) {
+	return getAs[bool](r, pth)
+}
+
+// String returns the value of a BYTE_ARRAY column
+func (r Row) String(pth string) (string, bool) {
+	return getAs[string](r, pth)
+}
+
+func getAs[V any](r Row, pth string) (V, bool) {
+	v, _ := r.Get(pth)
+	out, ok := v.(V)
+	return out, ok
+}
@@ -56,9 +56,21 @@ func (r RepetitionTypes) MaxRep() uint8 {
 	return out
 }
 
+// setAnnotation sets the annotation of the i'th of the n levels of a
+// column's path (the last level is the column itself).
+func setAnnotation(annotations []Annotation, n, i int, a Annotation) []Annotation {
+	if annotations == nil {
+		annotations = make([]Annotation, n)
+	}
+	annotations[i] = a
+	return annotations
+}
+
 // RequiredField writes the raw data for required columns
 type RequiredField struct {
 	pth         []string
+	id          int32
+	annotations []Annotation
 	compression sch.CompressionCodec
 }
 
@@ -92,6 +104,23 @@ func RequiredFieldUncompressed(r *RequiredField) {
 	r.compression = sch.CompressionCodec_UNCOMPRESSED
 }
 
+// RequiredFieldID sets the field_id of a column
+// It is an optional arg to NewRequiredField
+func RequiredFieldID(id int32) func(*RequiredField) {
+	return func(r *RequiredField) {
+		r.id = id
+	}
+}
+
+// RequiredFieldAnnotation sets the logical and converted type of the
+// i'th level of the column's path (the column is the last level).
+// It is an optional arg to NewRequiredField
+func RequiredFieldAnnotation(i int, lt *sch.LogicalType, ct *sch.ConvertedType) func(*RequiredField) {
+	return func(r *RequiredField) {
+		r.annotations = setAnnotation(r.annotations, len(r.pth), i, Annotation{LogicalType: lt, ConvertedType: ct})
+	}
+}
+
 // DoWrite writes the actual raw data.
 func (f *RequiredField) DoWrite(w io.Writer, meta *Metadata, vals []byte, count int, stats Stats) error {
 	buff := buffpool.Get()
@@ -134,6 +163,18 @@ func (f *RequiredField) DoRead(r io.ReadSeeker, pg Page) (io.Reader, []int, erro
 	return bytes.NewBuffer(out), sizes, nil
 }
 
+// DoReadPage reads a single page of the column chunk described by pg.
+// pg is moved past the page so that the next call reads the page
+// that follows it.  It returns the page data and the number of values
+// in the page.
+func (f *RequiredField) DoReadPage(r io.ReadSeeker, pg *Page) (io.Reader, int, error) {
+	ph, data, err := readPage(r, pg)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewBuffer(data), int(ph.DataPageHeader.NumValues), nil
+}
+
 // Name returns the column name of this field
 func (f *RequiredField) Name() string {
 	return strings.Join(f.pth, ".")
@@ -144,6 +185,17 @@ func (f *RequiredField) Path() []string {
 	return f.pth
 }
 
+// ID returns the field_id of this field (0 if it doesn't have one)
+func (f *RequiredField) ID() int32 {
+	return f.id
+}
+
+// Annotations returns the annotation of each level of the
+// field's path (nil if none of them have one)
+func (f *RequiredField) Annotations() []Annotation {
+	return f.annotations
+}
+
 // MaxLevel holds the maximum definition and
 // repeptition level for a given field.
 type MaxLevel struct {
@@ -157,6 +209,8 @@ type OptionalField struct {
 	Defs           []uint8
 	Reps           []uint8
 	pth            []string
+	id             int32
+	annotations    []Annotation
 	MaxLevels      MaxLevel
 	compression    sch.CompressionCodec
 	RepetitionType FieldFunc
@@ -211,6 +265,24 @@ func OptionalFieldUncompressed(o *OptionalField) {
 	o.compression = sch.CompressionCodec_UNCOMPRESSED
 }
 
+// OptionalFieldID sets the field_id of a column
+// It is an optional arg to NewOptionalField
+func OptionalFieldID(id int32) func(*OptionalField) {
+	return func(o *OptionalField) {
+		o.id = id
+	}
+}
+
+// OptionalFieldAnnotation sets the logical and converted type of the
+// i'th level of the column's path (the column is the last level), so
+// the groups of lists and maps can be annotated too.
+// It is an optional arg to NewOptionalField
+func OptionalFieldAnnotation(i int, lt *sch.LogicalType, ct *sch.ConvertedType) func(*OptionalField) {
+	return func(o *OptionalField) {
+		o.annotations = setAnnotation(o.annotations, len(o.pth), i, Annotation{LogicalType: lt, ConvertedType: ct})
+	}
+}
+
 // Values reads the definition levels and uses them
 // to return the values from the page data.
 func (f *OptionalField) Values() int {
@@ -316,6 +388,95 @@ func (f *OptionalField) DoRead(r io.ReadSeeker, pg Page) (io.Reader, []int, erro
 	return bytes.NewBuffer(out), sizes, nil
 }
 
+// DoReadPage reads a single page of the column chunk described by pg.
+// The page's definition and repetition levels are appended to f.Defs
+// and f.Reps.  It returns the page data and the number of values that
+// are not null.
+func (f *OptionalField) DoReadPage(r io.ReadSeeker, pg *Page) (io.Reader, int, error) {
+	ph, data, err := readPage(r, pg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	n := int(ph.DataPageHeader.NumValues)
+	var l int
+	if f.repeated {
+		reps, l2, err := readLevels(bytes.NewBuffer(data), int32(bits.Len(uint(f.MaxLevels.Rep))))
+		if err != nil {
+			return nil, 0, err
+		}
+		f.Reps = append(f.Reps, reps[:n]...)
+		l += l2
+	}
+
+	defs, l2, err := readLevels(bytes.NewBuffer(data[l:]), int32(bits.Len(uint(f.MaxLevels.Def))))
+	if err != nil {
+		return nil, 0, err
+	}
+	f.Defs = append(f.Defs, defs[:n]...)
+	l += l2
+
+	return bytes.NewBuffer(data[l:]), f.valsFromDefs(defs[:n], f.MaxLevels.Def), nil
+}
+
+// Skip discards the levels of up to n records.  Only records that are
+// followed by the first level of another record are discarded, so the last
+// record of a partially read column chunk is kept.  It returns the number of
+// records that were discarded and the number of values that belonged
+// to them.
+func (f *OptionalField) Skip(n int) (int, int) {
+	var recs, l int
+	if !f.repeated {
+		recs = min(n, len(f.Defs))
+		l = recs
+	} else {
+		for i := 1; i < len(f.Reps) && recs < n; i++ {
+			if f.Reps[i] == 0 {
+				recs++
+				l = i
+			}
+		}
+	}
+
+	vals := f.valsFromDefs(f.Defs[:l], f.MaxLevels.Def)
+	f.Defs = f.Defs[l:]
+	if f.repeated {
+		f.Reps = f.Reps[l:]
+	}
+	return recs, vals
+}
+
+// Buffered is true if the levels of the next record have been
+// read.  A repeated record is only complete once the first level
+// of the record after it has been read (or the column chunk has no
+// more pages).
+func (f *OptionalField) Buffered() bool {
+	if len(f.Defs) == 0 {
+		return false
+	}
+
+	if !f.repeated {
+		return true
+	}
+
+	for _, rep := range f.Reps[1:] {
+		if rep == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// LevelsSize estimates the number of bytes that the
+// definition and repetition levels take up once encoded.
+func (f *OptionalField) LevelsSize() int {
+	n := (len(f.Defs)*bits.Len8(f.MaxLevels.Def) + 7) / 8
+	if f.repeated {
+		n += (len(f.Reps)*bits.Len8(f.MaxLevels.Rep) + 7) / 8
+	}
+	return n
+}
+
 // Name returns the column name of this field
 func (f *OptionalField) Name() string {
 	return strings.Join(f.pth, ".")
@@ -326,6 +487,17 @@ func (f *OptionalField) Path() []string {
 	return f.pth
 }
 
+// ID returns the field_id of this field (0 if it doesn't have one)
+func (f *OptionalField) ID() int32 {
+	return f.id
+}
+
+// Annotations returns the annotation of each level of the
+// field's path (nil if none of them have one)
+func (f *OptionalField) Annotations() []Annotation {
+	return f.annotations
+}
+
 // writeCounter keeps track of the number of bytes written
 // it is used for calls to binary.Write, which does not
 // return the number of bytes written.
@@ -355,6 +527,29 @@ func (r *readCounter) Read(p []byte) (int, error) {
 	return n, err
 }
 
+// readPage seeks to pg.Offset and reads the page header and the
+// uncompressed page data.  pg is then updated to point at the next page.
+func readPage(r io.ReadSeeker, pg *Page) (*sch.PageHeader, []byte, error) {
+	if _, err := r.Seek(pg.Offset, io.SeekStart); err != nil {
+		return nil, nil, fmt.Errorf("unable to seek to offset %d, err: %s", pg.Offset, err)
+	}
+
+	rc := &readCounter{r: r}
+	ph, err := PageHeader(rc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := pageData(rc, ph, *pg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pg.Offset += rc.n
+	pg.Size -= int(rc.n)
+	return ph, data, nil
+}
+
 func pageData(r io.Reader, ph *sch.PageHeader, pg Page) ([]byte, error) {
 	var data []byte
 	switch pg.Codec {
@@ -1,6 +1,6 @@
 module github.com/parsyl/parquet
 
-go 1.20
+go 1.23
 
 require (
 	github.com/apache/thrift v0.18.1
@@ -0,0 +1,237 @@
+package parquet
+
+import (
+	"io"
+	"reflect"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// leafColumn holds the current page of a column whose type is only known
+// at runtime.  It does the work of a Column that doesn't depend on how the
+// values are read from and written to the records (see reflectColumn and
+// dynamicColumn).
+type leafColumn struct {
+	// req is set if every level of the column's path is required,
+	// otherwise opt is set.
+	req *RequiredField
+	opt *OptionalField
+
+	vals values
+	typ  FieldFunc
+
+	// types is the repetition type of each level of the path
+	// and reps is the repetition level of each level.
+	types []RepetitionType
+	reps  []uint8
+}
+
+func newLeafColumn(pth []string, types []RepetitionType, kind reflect.Kind, id int32, annotations []Annotation, c sch.CompressionCodec) *leafColumn {
+	k := kinds[kind]
+	out := &leafColumn{
+		vals:  k.newValues(),
+		typ:   k.typ,
+		types: types,
+	}
+
+	var rep uint8
+	for _, t := range types {
+		if t == Repeated {
+			rep++
+		}
+		out.reps = append(out.reps, rep)
+	}
+
+	// the columns of a struct without logical types have
+	// the same (nil) annotations as the generated columns
+	if !annotated(annotations) {
+		annotations = nil
+	}
+
+	if RepetitionTypes(types).MaxDef() == 0 {
+		f := NewRequiredField(pth, RequiredFieldID(id))
+		f.compression = c
+		f.annotations = annotations
+		out.req = &f
+		return out
+	}
+
+	ts := make([]int, len(types))
+	for i, t := range types {
+		ts[i] = int(t)
+	}
+
+	f := NewOptionalField(pth, ts, OptionalFieldID(id))
+	f.compression = c
+	f.annotations = annotations
+	out.opt = &f
+	return out
+}
+
+func (c *leafColumn) Schema() Field {
+	if c.req != nil {
+		types := make([]int, len(c.types))
+		return Field{Name: c.Name(), Path: c.req.Path(), Type: c.typ, RepetitionType: RepetitionRequired, Types: types, ID: c.req.ID(), Annotations: c.req.Annotations()}
+	}
+	return Field{Name: c.Name(), Path: c.opt.Path(), Type: c.typ, RepetitionType: c.opt.RepetitionType, Types: c.opt.Types, ID: c.opt.ID(), Annotations: c.opt.Annotations()}
+}
+
+func (c *leafColumn) Name() string {
+	if c.req != nil {
+		return c.req.Name()
+	}
+	return c.opt.Name()
+}
+
+// add adds a value and its levels.  v is invalid if the value is null.
+func (c *leafColumn) add(v reflect.Value, def, rep uint8) {
+	if v.IsValid() {
+		c.vals.add(v)
+	}
+
+	if c.opt != nil {
+		c.opt.Defs = append(c.opt.Defs, def)
+		if c.opt.repeated {
+			c.opt.Reps = append(c.opt.Reps, rep)
+		}
+	}
+}
+
+func (c *leafColumn) Write(w io.Writer, meta *Metadata) error {
+	buf := buffpool.Get()
+	defer buffpool.Put(buf)
+
+	if err := c.vals.encode(buf); err != nil {
+		return err
+	}
+
+	if c.req != nil {
+		return c.req.DoWrite(w, meta, buf.Bytes(), c.vals.len(), c.vals.stats(nil, 0))
+	}
+	return c.opt.DoWrite(w, meta, buf.Bytes(), len(c.opt.Defs), c.vals.stats(c.opt.Defs, c.opt.MaxLevels.Def))
+}
+
+func (c *leafColumn) Read(r io.ReadSeeker, pg Page) error {
+	if c.req != nil {
+		rr, sizes, err := c.req.DoRead(r, pg)
+		if err != nil {
+			return err
+		}
+		return c.vals.decode(rr, pg.Type, pg.N, sizes)
+	}
+
+	rr, sizes, err := c.opt.DoRead(r, pg)
+	if err != nil {
+		return err
+	}
+	return c.vals.decode(rr, pg.Type, c.opt.Values()-c.vals.len(), sizes)
+}
+
+func (c *leafColumn) ReadPage(r io.ReadSeeker, pg *Page) error {
+	if c.req != nil {
+		if c.vals.len() > 0 || pg.Done() {
+			return nil
+		}
+
+		rr, n, err := c.req.DoReadPage(r, pg)
+		if err != nil {
+			return err
+		}
+		return c.vals.decode(rr, pg.Type, n, []int{n})
+	}
+
+	for !c.opt.Buffered() && !pg.Done() {
+		rr, n, err := c.opt.DoReadPage(r, pg)
+		if err != nil {
+			return err
+		}
+
+		if err := c.vals.decode(rr, pg.Type, n, []int{n}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scan calls f with the levels of the next record and then discards
+// them.  indices is the index of the current element of each repeated
+// level and i is the index of the value (-1 if the value is null).
+func (c *leafColumn) scan(f func(def uint8, indices []int, i int)) {
+	if c.req != nil {
+		if c.vals.len() == 0 {
+			return
+		}
+
+		f(0, nil, 0)
+		c.vals.discard(1)
+		return
+	}
+
+	if len(c.opt.Defs) == 0 {
+		return
+	}
+
+	// l is the number of levels of the record (the levels up to
+	// the next repetition level of 0).
+	l := 1
+	for c.opt.repeated && l < len(c.opt.Reps) && c.opt.Reps[l] > 0 {
+		l++
+	}
+
+	indices := make([]int, c.opt.MaxLevels.Rep)
+	var n int
+	for i, def := range c.opt.Defs[:l] {
+		if i > 0 {
+			rep := c.opt.Reps[i]
+			indices[rep-1]++
+			for j := int(rep); j < len(indices); j++ {
+				indices[j] = 0
+			}
+		}
+
+		if def < c.opt.MaxLevels.Def {
+			f(def, indices, -1)
+			continue
+		}
+
+		f(def, indices, n)
+		n++
+	}
+
+	c.opt.Defs = c.opt.Defs[l:]
+	if c.opt.repeated {
+		c.opt.Reps = c.opt.Reps[l:]
+	}
+	c.vals.discard(n)
+}
+
+func (c *leafColumn) Skip(n int) int {
+	if c.req != nil {
+		n = min(n, c.vals.len())
+		c.vals.discard(n)
+		return n
+	}
+
+	n, v := c.opt.Skip(n)
+	c.vals.discard(v)
+	return n
+}
+
+func (c *leafColumn) Size() int {
+	if c.req != nil {
+		return c.vals.size()
+	}
+	return c.vals.size() + c.opt.LevelsSize()
+}
+
+func (c *leafColumn) Levels() ([]uint8, []uint8) {
+	if c.req != nil {
+		return nil, nil
+	}
+	return c.opt.Defs, c.opt.Reps
+}
+
+func (c *leafColumn) buffer() (any, []uint8, []uint8) {
+	d, r := c.Levels()
+	return c.vals.all(), d, r
+}
@@ -0,0 +1,264 @@
+package parquet
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// Merger merges parquet files that have the same schema by copying
+// their column chunks, so nothing is decoded or compressed again.
+type Merger struct {
+	// CoalesceBytes merges consecutive row groups (including row groups
+	// from different files) into a single row group as long as their
+	// total compressed size is at most CoalesceBytes.  The pages of the
+	// column chunks are concatenated, so row groups with dictionary pages
+	// or with different codecs aren't merged.  0 means that the row groups
+	// are kept as they are.
+	CoalesceBytes int64
+
+	// CreatedBy is the created_by of the merged file.  It
+	// defaults to the created_by of the first file.
+	CreatedBy string
+}
+
+// Merge merges srcs into dst with the default Merger
+func Merge(dst io.Writer, srcs ...io.ReadSeeker) error {
+	var m Merger
+	return m.Merge(dst, srcs...)
+}
+
+type mergeRowGroup struct {
+	src io.ReadSeeker
+	rg  *sch.RowGroup
+}
+
+// Merge writes a parquet file to dst that has all of the rows of srcs
+// (in order).  The files' schemas have to be the same.  The key_value_metadata
+// of the merged file is the key_value_metadata of the first file and its
+// column_orders are kept if every file has the same column_orders.
+func (m *Merger) Merge(dst io.Writer, srcs ...io.ReadSeeker) error {
+	if len(srcs) == 0 {
+		return fmt.Errorf("nothing to merge")
+	}
+
+	var first *sch.FileMetaData
+	var orders []*sch.ColumnOrder
+	var rowGroups []mergeRowGroup
+	for i, src := range srcs {
+		fmd, err := ReadMetaData(src)
+		if err != nil {
+			return fmt.Errorf("unable to read footer of file %d, err: %s", i, err)
+		}
+
+		if first == nil {
+			first = fmd
+			orders = fmd.ColumnOrders
+		} else if err := compareSchema(first.Schema, fmd.Schema); err != nil {
+			return fmt.Errorf("unable to merge file %d, err: %s", i, err)
+		} else if !sameColumnOrders(orders, fmd.ColumnOrders) {
+			orders = nil
+		}
+
+		for _, rg := range fmd.RowGroups {
+			rowGroups = append(rowGroups, mergeRowGroup{src: src, rg: rg})
+		}
+	}
+
+	out := &sch.FileMetaData{
+		Version:          1,
+		Schema:           first.Schema,
+		KeyValueMetadata: first.KeyValueMetadata,
+		CreatedBy:        first.CreatedBy,
+		ColumnOrders:     orders,
+	}
+
+	if m.CreatedBy != "" {
+		createdBy := m.CreatedBy
+		out.CreatedBy = &createdBy
+	}
+
+	w := &writeCounter{w: dst}
+	if _, err := w.Write(magic); err != nil {
+		return err
+	}
+
+	for _, group := range m.coalesce(rowGroups) {
+		rg, err := mergeRowGroups(w, group)
+		if err != nil {
+			return err
+		}
+		out.NumRows += rg.NumRows
+		out.RowGroups = append(out.RowGroups, rg)
+	}
+
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	buf, err := ts.Write(context.TODO(), out)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(buf))); err != nil {
+		return err
+	}
+
+	_, err = w.Write(magic)
+	return err
+}
+
+// coalesce groups the row groups that are merged into a single row group.
+// Only the row groups whose column chunks can be concatenated (see
+// sameChunks) are put in the same group.
+func (m *Merger) coalesce(rowGroups []mergeRowGroup) [][]mergeRowGroup {
+	var out [][]mergeRowGroup
+	var size int64
+	for _, rg := range rowGroups {
+		n := compressedSize(rg.rg)
+		i := len(out) - 1
+		if i < 0 || m.CoalesceBytes <= 0 || size+n > m.CoalesceBytes || hasDictionary(rg.rg) || hasDictionary(out[i][0].rg) || !sameChunks(out[i][0].rg, rg.rg) {
+			out = append(out, []mergeRowGroup{rg})
+			size = n
+			continue
+		}
+
+		out[i] = append(out[i], rg)
+		size += n
+	}
+	return out
+}
+
+// sameChunks is true if the column chunks of a and b have the same
+// paths and codecs, so the pages of b can follow the pages of a.
+func sameChunks(a, b *sch.RowGroup) bool {
+	if len(a.Columns) != len(b.Columns) {
+		return false
+	}
+
+	for i, ac := range a.Columns {
+		am, bm := ac.MetaData, b.Columns[i].MetaData
+		if am == nil || bm == nil || am.Codec != bm.Codec || strings.Join(am.PathInSchema, ".") != strings.Join(bm.PathInSchema, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeRowGroups copies the column chunks of group to w (which is
+// positioned right after the last column chunk that was copied) and
+// returns the merged row group.
+func mergeRowGroups(w *writeCounter, group []mergeRowGroup) (*sch.RowGroup, error) {
+	first := group[0].rg
+	out := &sch.RowGroup{
+		Columns: make([]*sch.ColumnChunk, len(first.Columns)),
+	}
+
+	for _, rg := range group {
+		if len(rg.rg.Columns) != len(first.Columns) {
+			return nil, fmt.Errorf("row groups have %d and %d columns", len(first.Columns), len(rg.rg.Columns))
+		}
+		out.NumRows += rg.rg.NumRows
+	}
+
+	for i, col := range first.Columns {
+		if col.MetaData == nil {
+			return nil, fmt.Errorf("column chunk %d has no metadata", i)
+		}
+
+		pos := w.n
+		md := *col.MetaData
+		md.NumValues = 0
+		md.TotalCompressedSize = 0
+		md.TotalUncompressedSize = 0
+		md.DataPageOffset = pos
+		md.IndexPageOffset = nil
+		md.EncodingStats = nil
+		if len(group) > 1 {
+			md.Statistics = nil
+		}
+
+		for _, rg := range group {
+			ch := rg.rg.Columns[i]
+			if ch.MetaData == nil || strings.Join(ch.MetaData.PathInSchema, ".") != strings.Join(col.MetaData.PathInSchema, ".") || ch.MetaData.Codec != col.MetaData.Codec {
+				return nil, fmt.Errorf("column chunk %d of the row groups don't match", i)
+			}
+
+			start := chunkOffset(ch)
+			if md.NumValues == 0 && ch.MetaData.DictionaryPageOffset != nil {
+				dict := pos
+				md.DictionaryPageOffset = &dict
+				md.DataPageOffset = pos + ch.MetaData.DataPageOffset - start
+			}
+
+			if _, err := rg.src.Seek(start, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("unable to seek to column chunk %s, err: %s", strings.Join(ch.MetaData.PathInSchema, "."), err)
+			}
+
+			if _, err := io.CopyN(w, rg.src, ch.MetaData.TotalCompressedSize); err != nil {
+				return nil, fmt.Errorf("unable to copy column chunk %s, err: %s", strings.Join(ch.MetaData.PathInSchema, "."), err)
+			}
+
+			md.NumValues += ch.MetaData.NumValues
+			md.TotalCompressedSize += ch.MetaData.TotalCompressedSize
+			md.TotalUncompressedSize += ch.MetaData.TotalUncompressedSize
+		}
+
+		out.TotalByteSize += md.TotalCompressedSize
+		out.Columns[i] = &sch.ColumnChunk{
+			FileOffset: pos,
+			MetaData:   &md,
+		}
+	}
+
+	return out, nil
+}
+
+// sameColumnOrders is true if a and b have the same column orders
+func sameColumnOrders(a, b []*sch.ColumnOrder) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// chunkOffset is where the first page of a column chunk starts
+func chunkOffset(ch *sch.ColumnChunk) int64 {
+	if ch.MetaData.DictionaryPageOffset != nil && *ch.MetaData.DictionaryPageOffset < ch.MetaData.DataPageOffset {
+		return *ch.MetaData.DictionaryPageOffset
+	}
+	return ch.MetaData.DataPageOffset
+}
+
+func compressedSize(rg *sch.RowGroup) int64 {
+	var n int64
+	for _, ch := range rg.Columns {
+		if ch.MetaData != nil {
+			n += ch.MetaData.TotalCompressedSize
+		}
+	}
+	return n
+}
+
+func hasDictionary(rg *sch.RowGroup) bool {
+	for _, ch := range rg.Columns {
+		if ch.MetaData != nil && ch.MetaData.DictionaryPageOffset != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -4,17 +4,25 @@
 package parquet
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/apache/thrift/lib/go/thrift"
 	sch "github.com/parsyl/parquet/schema"
 )
 
+var magic = []byte("PAR1")
+
+// CreatedBy is the default created_by of the files that are written
+const CreatedBy = "github.com/parsyl/parquet"
+
 // Field holds the type information for a parquet column
 type Field struct {
 	Name           string
@@ -22,6 +30,26 @@ type Field struct {
 	Types          []int
 	Type           FieldFunc
 	RepetitionType FieldFunc
+	// ID is the field_id of the column (0 means it doesn't have one)
+	ID int32
+	// Annotations are the logical and converted types of each level
+	// of Path (nil if none of them have one)
+	Annotations []Annotation
+}
+
+// annotate sets the logical and converted type of se,
+// which is the element of the i'th level of f's path.
+func (f Field) annotate(se *sch.SchemaElement, i int) {
+	if i >= len(f.Annotations) {
+		return
+	}
+
+	a := f.Annotations[i]
+	if a.LogicalType == nil && a.ConvertedType == nil {
+		return
+	}
+
+	a.Set(se)
 }
 
 // Page keeps track of metadata for each ColumnChunk
@@ -31,6 +59,15 @@ type Page struct {
 	Size   int
 	Offset int64
 	Codec  sch.CompressionCodec
+	// Type is the physical type of the column in the file,
+	// which can be narrower than the type of the field.
+	Type sch.Type
+}
+
+// Done is true once all of the pages of the column chunk
+// have been read with DoReadPage.
+func (p Page) Done() bool {
+	return p.Size <= 0
 }
 
 type schema struct {
@@ -48,35 +85,42 @@ func (s schema) schema() (int64, []*sch.SchemaElement) {
 	var z int32
 	m := map[string]*sch.SchemaElement{}
 	for _, f := range s.fields {
-		if len(f.Path) > 1 {
-			for i, name := range f.Path[:len(f.Path)-1] {
-				par, ok := m[name]
-				if !ok {
-					children++
-					parts := strings.Split(name, ".")
-					rt := sch.FieldRepetitionType(f.Types[i])
-					par = &sch.SchemaElement{
-						Name:           parts[len(parts)-1],
-						RepetitionType: &rt,
-						NumChildren:    &z,
-					}
-					out = append(out, par)
+		// par is the group that the next element is a child of
+		// (nil for the root) and each group is only counted as a
+		// child of its parent when it is created.
+		var par *sch.SchemaElement
+		for i, name := range f.Path[:len(f.Path)-1] {
+			key := strings.Join(f.Path[:i+1], ".")
+			grp, ok := m[key]
+			if !ok {
+				addChild(par, &children)
+				parts := strings.Split(name, ".")
+				rt := sch.FieldRepetitionType(f.Types[i])
+				grp = &sch.SchemaElement{
+					Name:           parts[len(parts)-1],
+					RepetitionType: &rt,
+					NumChildren:    &z,
 				}
-				n := *par.NumChildren
-				n++
-				par.NumChildren = &n
-				m[name] = par
+				f.annotate(grp, i)
+				out = append(out, grp)
+				m[key] = grp
 			}
-		} else if len(f.Path) == 1 {
-			children++
+			par = grp
 		}
+		addChild(par, &children)
 
 		se := &sch.SchemaElement{
 			Name: f.Path[len(f.Path)-1],
 		}
 
+		if f.ID != 0 {
+			id := f.ID
+			se.FieldID = &id
+		}
+
 		f.Type(se)
 		f.RepetitionType(se)
+		f.annotate(se, len(f.Path)-1)
 		out = append(out, se)
 	}
 
@@ -84,10 +128,22 @@ func (s schema) schema() (int64, []*sch.SchemaElement) {
 	return int64(len(s.fields)), out
 }
 
+func addChild(par *sch.SchemaElement, root *int32) {
+	if par == nil {
+		*root++
+		return
+	}
+	n := par.GetNumChildren() + 1
+	par.NumChildren = &n
+}
+
 // Metadata keeps track of the things that need to
 // be kept track of in order to write the FileMetaData
 // at the end of the parquet file.
 type Metadata struct {
+	// mu protects ts and rowGroups so that the column
+	// chunks can be written by different goroutines.
+	mu           sync.Mutex
 	ts           *thrift.TSerializer
 	schema       schema
 	docs         int64
@@ -95,9 +151,34 @@ type Metadata struct {
 	rowGroupDocs int64
 	rowGroups    []RowGroup
 
+	createdBy string
+	keyValue  map[string]string
+
+	// appended and start are set by Append.  appended are the row groups
+	// that are already in the file and start is where the first new row
+	// group is written.
+	appended []*sch.RowGroup
+	start    int64
+
+	// end is where the footer has to end when the old footer of an
+	// appended file wasn't truncated (0 if it doesn't matter).
+	end int64
+
+	// columns maps the columns of the file that was read by ReadFooter
+	// to the columns of the schema (see Column).
+	columns         map[string]string
+	caseInsensitive bool
+
 	metadata *sch.FileMetaData
 }
 
+// FileMetadata is the information in the footer of a
+// parquet file that isn't about its schema or data.
+type FileMetadata struct {
+	CreatedBy string
+	KeyValue  map[string]string
+}
+
 // Stats is passed in by each column's call to DoWrite
 type Stats interface {
 	NullCount() *int64
@@ -112,8 +193,9 @@ func New(fields ...Field) *Metadata {
 	ts := thrift.NewTSerializer()
 	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
 	m := &Metadata{
-		ts:     ts,
-		schema: schemaElements(fields),
+		ts:        ts,
+		schema:    schemaElements(fields),
+		createdBy: CreatedBy,
 	}
 
 	m.StartRowGroup(fields...)
@@ -170,14 +252,18 @@ func (m *Metadata) WritePageHeader(w io.Writer, pth []string, dataLen, compresse
 		},
 	}
 
+	m.mu.Lock()
 	m.pageDocs = 0
 
 	buf, err := m.ts.Write(context.TODO(), ph)
 	if err != nil {
+		m.mu.Unlock()
 		return err
 	}
 
-	if err := m.updateRowGroup(pth, dataLen, compressedLen, len(buf), count, comp); err != nil {
+	err = m.updateRowGroup(pth, dataLen, compressedLen, len(buf), count, comp)
+	m.mu.Unlock()
+	if err != nil {
 		return err
 	}
 
@@ -213,6 +299,66 @@ func (m *Metadata) Rows() int64 {
 	return m.metadata.NumRows
 }
 
+// CompressionRatio is the compressed size divided by the uncompressed
+// size of all of the column chunks written so far.  It is 1 until the
+// first column chunk has been written.
+func (m *Metadata) CompressionRatio() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var compressed, uncompressed int64
+	for _, rg := range m.rowGroups {
+		for _, ch := range rg.columns {
+			if ch.MetaData == nil {
+				continue
+			}
+			compressed += ch.MetaData.TotalCompressedSize
+			uncompressed += ch.MetaData.TotalUncompressedSize
+		}
+	}
+
+	if uncompressed == 0 {
+		return 1
+	}
+	return float64(compressed) / float64(uncompressed)
+}
+
+// SetCreatedBy sets the created_by that is written by Footer.
+// It is left out of the footer if it is empty.
+func (m *Metadata) SetCreatedBy(s string) {
+	m.createdBy = s
+}
+
+// SetKeyValueMetadata sets the key_value_metadata that is written by Footer
+func (m *Metadata) SetKeyValueMetadata(kv map[string]string) {
+	m.keyValue = kv
+}
+
+// FileMetadata returns the created_by and key_value_metadata
+// of the footer that was read by ReadFooter.
+func (m *Metadata) FileMetadata() FileMetadata {
+	var out FileMetadata
+	if m.metadata == nil {
+		return out
+	}
+
+	if m.metadata.CreatedBy != nil {
+		out.CreatedBy = *m.metadata.CreatedBy
+	}
+
+	if len(m.metadata.KeyValueMetadata) > 0 {
+		out.KeyValue = make(map[string]string, len(m.metadata.KeyValueMetadata))
+		for _, kv := range m.metadata.KeyValueMetadata {
+			var v string
+			if kv.Value != nil {
+				v = *kv.Value
+			}
+			out.KeyValue[kv.Key] = v
+		}
+	}
+	return out
+}
+
 // Footer writes the FileMetaData at the end of the file.
 func (m *Metadata) Footer(w io.Writer) error {
 	_, s := m.schema.schema()
@@ -223,7 +369,27 @@ func (m *Metadata) Footer(w io.Writer) error {
 		RowGroups: make([]*sch.RowGroup, 0, len(m.rowGroups)),
 	}
 
+	if m.createdBy != "" {
+		createdBy := m.createdBy
+		fmd.CreatedBy = &createdBy
+	}
+
+	keys := make([]string, 0, len(m.keyValue))
+	for k := range m.keyValue {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := m.keyValue[k]
+		fmd.KeyValueMetadata = append(fmd.KeyValueMetadata, &sch.KeyValue{Key: k, Value: &v})
+	}
+
 	pos := int64(4)
+	if m.start > 0 {
+		pos = m.start
+		fmd.RowGroups = append(fmd.RowGroups, m.appended...)
+	}
+
 	for _, mrg := range m.rowGroups {
 		rg := mrg.rowGroup
 		if rg.NumRows == 0 {
@@ -251,6 +417,14 @@ func (m *Metadata) Footer(w io.Writer) error {
 		return err
 	}
 
+	// the zeros before the footer (which is followed by its
+	// size and the magic bytes) aren't part of any column chunk
+	if pad := m.end - (pos + int64(len(buf)) + 8); pad > 0 {
+		if _, err := w.Write(make([]byte, pad)); err != nil {
+			return err
+		}
+	}
+
 	n, err := w.Write(buf)
 	if err != nil {
 		return err
@@ -312,13 +486,15 @@ func schemaElements(fields []Field) schema {
 
 		f.Type(&se)
 		f.RepetitionType(&se)
+		f.annotate(&se, len(f.Path)-1)
 		m[strings.Join(f.Path, ".")] = se
 	}
 
 	return schema{lookup: m, fields: fields}
 }
 
-// Pages maps each column name to its Pages
+// Pages maps each column name (of m's schema) to its Pages.
+// Columns that aren't in m's schema are left out.
 func (m *Metadata) Pages() (map[string][]Page, error) {
 	if len(m.metadata.RowGroups) == 0 {
 		return nil, nil
@@ -326,10 +502,9 @@ func (m *Metadata) Pages() (map[string][]Page, error) {
 	out := map[string][]Page{}
 	for _, rg := range m.metadata.RowGroups {
 		for _, ch := range rg.Columns {
-			pth := ch.MetaData.PathInSchema
-			_, ok := m.schema.lookup[strings.Join(pth, ".")]
+			k, ok := m.Column(ch.MetaData.PathInSchema)
 			if !ok {
-				return nil, fmt.Errorf("could not find schema for %v", pth)
+				continue
 			}
 
 			pg := Page{
@@ -337,8 +512,8 @@ func (m *Metadata) Pages() (map[string][]Page, error) {
 				Offset: ch.FileOffset,
 				Size:   int(ch.MetaData.TotalCompressedSize),
 				Codec:  ch.MetaData.Codec,
+				Type:   ch.MetaData.Type,
 			}
-			k := strings.Join(pth, ".")
 			out[k] = append(out[k], pg)
 		}
 	}
@@ -362,10 +537,184 @@ func ReadMetaData(r io.ReadSeeker) (*sch.FileMetaData, error) {
 	return m, m.Read(context.TODO(), p)
 }
 
+// ReadMetaDataAt reads the FileMetaData from the end of a parquet file
+// of the given size.  Unlike ReadMetaData it only uses ReadAt, so r can be
+// shared by readers in different goroutines.
+func ReadMetaDataAt(r io.ReaderAt, size int64) (*sch.FileMetaData, error) {
+	if size < 12 {
+		return nil, fmt.Errorf("file is too small (%d bytes) to be a parquet file", size)
+	}
+
+	buf := make([]byte, 8)
+	if err := readAt(r, buf, size-8); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(buf[4:], magic) {
+		return nil, fmt.Errorf("file does not end with %s", magic)
+	}
+
+	n := int64(binary.LittleEndian.Uint32(buf[:4]))
+	if n > size-12 {
+		return nil, fmt.Errorf("invalid metadata size %d for a file of %d bytes", n, size)
+	}
+
+	buf = make([]byte, n)
+	if err := readAt(r, buf, size-8-n); err != nil {
+		return nil, err
+	}
+
+	p := thrift.NewTCompactProtocol(&thrift.StreamTransport{Reader: bytes.NewReader(buf)})
+	m := sch.NewFileMetaData()
+	return m, m.Read(context.TODO(), p)
+}
+
+// Append reads the footer of the parquet file in r so that the row groups
+// that are written next are added to the ones that are already in the file.
+// The file's schema has to match the schema of m.  It returns the offset of
+// the old footer, which is where the new row groups have to be written.  The
+// file's key_value_metadata is kept unless SetKeyValueMetadata was called.
+func (m *Metadata) Append(r io.ReadSeeker) (int64, error) {
+	fmd, err := ReadMetaData(r)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read footer, err: %s", err)
+	}
+
+	size, err := getMetaDataSize(r)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read footer size, err: %s", err)
+	}
+
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	_, s := m.schema.schema()
+	if err := compareSchema(s, fmd.Schema); err != nil {
+		return 0, err
+	}
+
+	m.appended = fmd.RowGroups
+	m.start = end - int64(size) - 8
+	m.docs = fmd.NumRows
+
+	if m.keyValue == nil && len(fmd.KeyValueMetadata) > 0 {
+		m.keyValue = make(map[string]string, len(fmd.KeyValueMetadata))
+		for _, kv := range fmd.KeyValueMetadata {
+			var v string
+			if kv.Value != nil {
+				v = *kv.Value
+			}
+			m.keyValue[kv.Key] = v
+		}
+	}
+	return m.start, nil
+}
+
+// compareSchema returns an error if the schema of a file (actual)
+// doesn't have the same columns as the expected schema.  The columns
+// are compared by their paths (see leaves) so that files written with
+// the num_children of earlier versions of this package still match.
+func compareSchema(expected, actual []*sch.SchemaElement) error {
+	enames, e := leaves(expected)
+	anames, a := leaves(actual)
+	if len(enames) != len(anames) {
+		return fmt.Errorf("schema doesn't match, expected %d columns, got %d", len(enames), len(anames))
+	}
+
+	for i, col := range enames {
+		if anames[i] != col {
+			return fmt.Errorf("schema doesn't match, expected column %s, got %s", col, anames[i])
+		}
+
+		el, al := e[col], a[col]
+		diffs := elementDiffs(el.se, al.se)
+		if er, ar := repetitions(el.reps), repetitions(al.reps); er != ar {
+			diffs = append(diffs, fmt.Sprintf("repetition expected %s, got %s", er, ar))
+		}
+
+		if len(diffs) > 0 {
+			return fmt.Errorf("schema doesn't match for %s: %s", col, strings.Join(diffs, ", "))
+		}
+	}
+	return nil
+}
+
+// elementDiffs describes the differences between the
+// type and logical type of two columns.
+func elementDiffs(expected, actual *sch.SchemaElement) []string {
+	var out []string
+	if e, a := physicalType(expected), physicalType(actual); e != a {
+		out = append(out, fmt.Sprintf("type expected %s, got %s", e, a))
+	}
+
+	if e, a := logicalType(expected), logicalType(actual); e != a {
+		out = append(out, fmt.Sprintf("logical type expected %s, got %s", e, a))
+	}
+	return out
+}
+
+// physicalType is the type of a column or GROUP
+func physicalType(se *sch.SchemaElement) string {
+	if !se.IsSetType() {
+		return "GROUP"
+	}
+	return se.GetType().String()
+}
+
 // ReadFooter reads the parquet metadata
 func (m *Metadata) ReadFooter(r io.ReadSeeker) error {
 	meta, err := ReadMetaData(r)
 	m.metadata = meta
+	if err == nil {
+		m.columns = m.resolveColumns()
+	}
+	return err
+}
+
+// ReadFooterAt reads the parquet metadata with ReadMetaDataAt
+func (m *Metadata) ReadFooterAt(r io.ReaderAt, size int64) error {
+	meta, err := ReadMetaDataAt(r, size)
+	m.metadata = meta
+	if err == nil {
+		m.columns = m.resolveColumns()
+	}
+	return err
+}
+
+// NewReaderAt turns r into an io.ReaderAt.  Each call to ReadAt seeks r
+// before reading from it so the calls are serialized with a mutex.
+func NewReaderAt(r io.ReadSeeker) io.ReaderAt {
+	return &readerAt{r: r}
+}
+
+type readerAt struct {
+	mu sync.Mutex
+	r  io.ReadSeeker
+}
+
+// ReadAt makes readerAt an io.ReaderAt
+func (r *readerAt) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.r.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(r.r, p)
+}
+
+// readAt fills buf or returns an error
+func readAt(r io.ReaderAt, buf []byte, off int64) error {
+	n, err := r.ReadAt(buf, off)
+	if n == len(buf) {
+		return nil
+	}
+
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
 	return err
 }
 
@@ -428,6 +777,43 @@ func PageHeadersAtOffset(r io.ReadSeeker, o, n int64) ([]sch.PageHeader, error)
 	return out, nil
 }
 
+// OffsetIndex reads the page locations of a column chunk.  It returns
+// nil if the column chunk was written without an offset index.
+func OffsetIndex(r io.ReadSeeker, ch *sch.ColumnChunk) (*sch.OffsetIndex, error) {
+	if ch.OffsetIndexOffset == nil {
+		return nil, nil
+	}
+
+	if _, err := r.Seek(*ch.OffsetIndexOffset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to seek to offset index %d, err: %s", *ch.OffsetIndexOffset, err)
+	}
+
+	p := thrift.NewTCompactProtocol(&thrift.StreamTransport{Reader: r})
+	oi := sch.NewOffsetIndex()
+	return oi, oi.Read(context.TODO(), p)
+}
+
+// SeekPage moves pg to the page of an offset index that contains
+// row (relative to the start of the row group).  It returns the number
+// of rows that need to be skipped within that page in order to get to row.
+func SeekPage(pg *Page, oi *sch.OffsetIndex, row int64) int64 {
+	var loc *sch.PageLocation
+	for _, l := range oi.PageLocations {
+		if l.FirstRowIndex > row {
+			break
+		}
+		loc = l
+	}
+
+	if loc == nil || loc.Offset < pg.Offset {
+		return row
+	}
+
+	pg.Size -= int(loc.Offset - pg.Offset)
+	pg.Offset = loc.Offset
+	return row - loc.FirstRowIndex
+}
+
 // FieldFunc is used to set some of the metadata for each column
 type FieldFunc func(*sch.SchemaElement)
 
@@ -2,16 +2,25 @@ package parquet_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/parsyl/parquet"
+	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/fieldid"
+	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/logical"
+	"github.com/parsyl/parquet/cmd/parquetgen/message"
 	sch "github.com/parsyl/parquet/schema"
 	"github.com/stretchr/testify/assert"
 )
@@ -458,30 +467,1557 @@ func TestParquet(t *testing.T) {
 				if tc.pageSize == 0 {
 					tc.pageSize = 100
 				}
-				var buf bytes.Buffer
-				w, err := NewParquetWriter(&buf, MaxPageSize(tc.pageSize), compressionTest[comp])
-				assert.Nil(t, err, tc.name)
-				for _, rowgroup := range tc.input {
-					for _, p := range rowgroup {
-						w.Add(p)
+				b := writePeople(t, tc.input, parquet.MaxPageSize(tc.pageSize), compressionTest[comp])
+
+				r, err := NewParquetReader(bytes.NewReader(b))
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				expected := tc.expected
+				if expected == nil {
+					expected = tc.input
+				}
+
+				if !assert.Equal(t, getLen(expected), int(r.Rows()), tc.name) {
+					return
+				}
+
+				var i int
+				for r.Next() {
+					var p Person
+					r.Scan(&p)
+					exp := getExpected(expected, i)
+					assert.Equal(t, *exp, p, fmt.Sprintf("%s-%d", tc.name, i))
+					i++
+				}
+
+				assert.Nil(t, r.Error(), tc.name)
+				assert.Equal(t, getLen(expected), i, tc.name)
+			})
+		}
+	}
+}
+
+func TestStreamPages(t *testing.T) {
+	type testCase struct {
+		name     string
+		input    [][]Person
+		pageSize int
+	}
+
+	testCases := []testCase{
+		{
+			name:     "lots of people small page size",
+			pageSize: 7,
+			input:    getPeople(100, 1000),
+		},
+		{
+			name:     "boolean optional really large amount small page size",
+			pageSize: 2,
+			input:    getOptBools(301),
+		},
+		{
+			name:     "repeated and nested",
+			pageSize: 2,
+			input: [][]Person{
+				{
+					{Friends: []Being{{ID: 1, Age: pint32(10)}}},
+					{Code: pstring("c")},
+					{Friends: []Being{{ID: 2, Age: pint32(12)}, {ID: 3}, {ID: 4, Age: pint32(14)}}},
+					{
+						Hobby: &Hobby{
+							Name:       "napping",
+							Difficulty: pint32(10),
+							Skills: []Skill{
+								{Name: "meditation", Difficulty: "very"},
+								{Name: "calmness", Difficulty: "so-so"},
+							},
+						},
+					},
+				},
+				{
+					{Code: pstring("g")},
+					{Friends: []Being{{ID: 6, Age: pint32(20)}, {ID: 7, Age: pint32(22)}}},
+					{Hobby: &Hobby{Name: "sleeping"}},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, comp := range compressionCases {
+			t.Run(fmt.Sprintf("%s %s", tc.name, comp), func(t *testing.T) {
+				b := writePeople(t, tc.input, parquet.MaxPageSize(tc.pageSize), compressionTest[comp])
+
+				r, err := NewParquetReader(bytes.NewReader(b), parquet.StreamPages)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				var i int
+				for r.Next() {
+					var p Person
+					r.Scan(&p)
+					assert.Equal(t, *getExpected(tc.input, i), p, fmt.Sprintf("%s-%d", tc.name, i))
+					i++
+				}
+
+				assert.NoError(t, r.Error())
+				assert.Equal(t, getLen(tc.input), i)
+			})
+		}
+	}
+}
+
+func TestSeekToRow(t *testing.T) {
+	input := getPeople(100, 1000)
+	for i, rg := range input {
+		for j := range rg {
+			for k := 0; k < (i+j)%3; k++ {
+				rg[j].Friends = append(rg[j].Friends, Being{ID: int32(k), Age: pint32(int32(j))})
+			}
+		}
+	}
+
+	b := writePeople(t, input, parquet.MaxPageSize(7))
+
+	opts := map[string][]parquet.ReaderOption{
+		"row groups": nil,
+		"pages":      {parquet.StreamPages},
+	}
+
+	for name, opt := range opts {
+		for _, row := range []int64{0, 1, 6, 7, 99, 100, 555, 999, 1000} {
+			t.Run(fmt.Sprintf("%s %d", name, row), func(t *testing.T) {
+				r, err := NewParquetReader(bytes.NewReader(b), opt...)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				if !assert.NoError(t, r.SeekToRow(row)) {
+					return
+				}
+
+				i := int(row)
+				for r.Next() && i < int(row)+150 {
+					var p Person
+					r.Scan(&p)
+					assert.Equal(t, *getExpected(input, i), p, fmt.Sprintf("%s-%d", name, i))
+					i++
+				}
+				assert.NoError(t, r.Error())
+			})
+		}
+	}
+
+	r, err := NewParquetReader(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Error(t, r.SeekToRow(1001))
+}
+
+func TestReadRowGroup(t *testing.T) {
+	input := getPeople(100, 1000)
+	b := writePeople(t, input, parquet.MaxPageSize(30))
+
+	r, err := NewParquetReader(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if !assert.Equal(t, len(input), len(r.RowGroups())) {
+		return
+	}
+
+	for _, i := range []int{3, 0, 5, 9} {
+		if !assert.NoError(t, r.ReadRowGroup(i)) {
+			return
+		}
+
+		for j := 0; j < int(r.RowGroups()[i].Rows); j++ {
+			if !assert.True(t, r.Next()) {
+				return
+			}
+			var p Person
+			r.Scan(&p)
+			assert.Equal(t, input[i][j], p, fmt.Sprintf("%d-%d", i, j))
+		}
+	}
+
+	assert.False(t, r.Next())
+	assert.Error(t, r.ReadRowGroup(10))
+}
+
+func TestReadBatch(t *testing.T) {
+	input := getPeople(100, 1000)
+	b := writePeople(t, input, parquet.MaxPageSize(30))
+
+	opts := map[string][]parquet.ReaderOption{
+		"row groups": nil,
+		"pages":      {parquet.StreamPages},
+	}
+
+	for name, opt := range opts {
+		for _, size := range []int{1, 7, 100, 333, 2000} {
+			t.Run(fmt.Sprintf("%s %d", name, size), func(t *testing.T) {
+				r, err := NewParquetReader(bytes.NewReader(b), opt...)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				var i int
+				dst := make([]Person, size)
+				for {
+					n, err := r.ReadBatch(dst)
+					if err == io.EOF {
+						break
+					}
+
+					if !assert.NoError(t, err) || !assert.True(t, n > 0) {
+						return
+					}
+
+					for _, p := range dst[:n] {
+						assert.Equal(t, *getExpected(input, i), p, fmt.Sprintf("%s-%d", name, i))
+						i++
 					}
-					assert.Nil(t, w.Write(), tc.name)
+					clear(dst)
 				}
+				assert.Equal(t, getLen(input), i)
+			})
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	input := getPeople(100, 250)
+	b := writePeople(t, input, parquet.MaxPageSize(30))
+
+	r, err := NewParquetReader(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var i int
+	for p, err := range r.All() {
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, *getExpected(input, i), p, fmt.Sprintf("%d", i))
+		i++
+
+		if i == 120 {
+			break
+		}
+	}
+
+	// the iteration picks up where it stopped
+	for p, err := range r.All() {
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, *getExpected(input, i), p, fmt.Sprintf("%d", i))
+		i++
+	}
+	assert.Equal(t, getLen(input), i)
+
+	// a truncated column chunk is yielded as an error
+	m, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	bad := append([]byte{}, b...)
+	ch := m.RowGroups[1].Columns[0].MetaData
+	for j := ch.DataPageOffset; j < ch.DataPageOffset+ch.TotalCompressedSize; j++ {
+		bad[j] = 0xff
+	}
+
+	r, err = NewParquetReader(bytes.NewReader(bad))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var errs int
+	for _, err := range r.All() {
+		if err != nil {
+			errs++
+		}
+	}
+	assert.Equal(t, 1, errs)
+}
+
+func TestReadColumn(t *testing.T) {
+	input := getPeople(100, 300)
+	b := writePeople(t, input, parquet.MaxPageSize(30))
+
+	r, err := NewParquetReader(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var ids []int32
+	var ages []int32
+	var ageDefs []uint8
+	for _, p := range input[0] {
+		ids = append(ids, p.ID)
+		if p.Age == nil {
+			ageDefs = append(ageDefs, 0)
+		} else {
+			ages = append(ages, *p.Age)
+			ageDefs = append(ageDefs, 1)
+		}
+	}
+
+	vals, defs, reps, err := r.ReadColumnInt32("id")
+	assert.NoError(t, err)
+	assert.Equal(t, ids, vals)
+	assert.Nil(t, defs)
+	assert.Nil(t, reps)
+
+	vals, defs, reps, err = r.ReadColumnInt32("age")
+	assert.NoError(t, err)
+	assert.Equal(t, ages, vals)
+	assert.Equal(t, ageDefs, defs)
+	assert.Nil(t, reps)
+
+	names, _, _, err := r.ReadColumnString("name")
+	assert.NoError(t, err)
+	assert.Len(t, names, 100)
+	assert.Equal(t, input[0][3].Name, names[3])
+
+	vals, defs, reps, err = r.ReadColumnInt32("friends.id")
+	assert.NoError(t, err)
+	assert.Equal(t, len(defs), len(reps))
+	var n int
+	for _, d := range defs {
+		if d == 1 {
+			n++
+		}
+	}
+	assert.Equal(t, n, len(vals))
+
+	// only the rows that haven't been scanned are returned
+	for i := 0; i < 10 && r.Next(); i++ {
+		var p Person
+		r.Scan(&p)
+	}
+
+	vals, _, _, err = r.ReadColumnInt32("id")
+	assert.NoError(t, err)
+	assert.Equal(t, ids[10:], vals)
+
+	if !assert.NoError(t, r.ReadRowGroup(2)) {
+		return
+	}
+
+	happiness, _, _, err := r.ReadColumnInt64("happiness")
+	assert.NoError(t, err)
+	if assert.Len(t, happiness, 100) {
+		assert.Equal(t, input[2][0].Happiness, happiness[0])
+	}
+
+	_, _, _, err = r.ReadColumnInt64("id")
+	assert.EqualError(t, err, "unable to read column id, err: it has []int32 values, not []int64")
+
+	_, _, _, err = r.ReadColumnInt32("nope")
+	assert.EqualError(t, err, "unable to read column nope, err: it is not in the schema")
+
+	rr, err := parquet.NewReader[Person](bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	vals, _, _, err = rr.ReadColumnInt32("id")
+	assert.NoError(t, err)
+	assert.Equal(t, ids, vals)
+
+	r, err = NewParquetReader(bytes.NewReader(b), parquet.StreamPages)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, _, _, err = r.ReadColumnInt32("id")
+	assert.EqualError(t, err, "unable to read column id, err: the reader streams pages")
+}
+
+func TestParquetReaderAt(t *testing.T) {
+	input := getPeople(100, 1000)
+	b := writePeople(t, input, parquet.MaxPageSize(30))
+
+	// all of the readers share the same io.ReaderAt
+	ra := bytes.NewReader(b)
+	out := make([][]Person, len(input))
+	errs := make([]error, len(input))
+
+	var wg sync.WaitGroup
+	for i := range input {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, err := NewParquetReaderAt(ra, int64(len(b)))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			if err := r.ReadRowGroup(i); err != nil {
+				errs[i] = err
+				return
+			}
+
+			for j := int64(0); j < r.RowGroups()[i].Rows && r.Next(); j++ {
+				var p Person
+				r.Scan(&p)
+				out[i] = append(out[i], p)
+			}
+			errs[i] = r.Error()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range input {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, input[i], out[i])
+	}
+
+	_, err := NewParquetReaderAt(ra, 4)
+	assert.Error(t, err)
+}
+
+func TestConcurrency(t *testing.T) {
+	input := getPeople(100, 1000)
+	for i, rg := range input {
+		for j := range rg {
+			if j%4 == 0 {
+				rg[j].Friends = []Being{{ID: int32(i)}, {ID: int32(j), Age: pint32(2)}}
+			}
+		}
+	}
+
+	b := writePeople(t, input, parquet.MaxPageSize(30))
+
+	r, err := NewParquetReader(bytes.NewReader(b), parquet.WithConcurrency(4))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var i int
+	var jumped bool
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		assert.Equal(t, *getExpected(input, i), p, fmt.Sprint(i))
+		i++
+
+		// jump back while the next row group is being prefetched
+		if i == 550 && !jumped {
+			assert.NoError(t, r.ReadRowGroup(2))
+			i = 200
+			jumped = true
+		}
+	}
+
+	assert.NoError(t, r.Error())
+	assert.Equal(t, getLen(input), i)
+}
+
+func TestWriterConcurrency(t *testing.T) {
+	input := getPeople(100, 1000)
+	for i, rg := range input {
+		for j := range rg {
+			if j%3 == 0 {
+				rg[j].Friends = []Being{{ID: int32(i)}, {ID: int32(j), Age: pint32(2)}}
+			}
+		}
+	}
+
+	write := func(opts ...parquet.WriterOption) []byte {
+		return writePeople(t, input, append(opts, parquet.MaxPageSize(30))...)
+	}
+
+	expected := write()
+	actual := write(parquet.WriterConcurrency(4))
+	if !assert.Equal(t, expected, actual) {
+		return
+	}
+
+	r, err := NewParquetReader(bytes.NewReader(actual))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var i int
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		assert.Equal(t, *getExpected(input, i), p, fmt.Sprint(i))
+		i++
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, getLen(input), i)
+}
+
+func TestTargetBytes(t *testing.T) {
+	input := getPeople(10000, 10000)
+
+	b := writePeople(t, input[:1], parquet.Uncompressed, parquet.TargetPageBytes(2048), parquet.TargetRowGroupBytes(64*1024))
+
+	rd := bytes.NewReader(b)
+	footer, err := parquet.ReadMetaData(rd)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, len(footer.RowGroups) > 1, fmt.Sprint(len(footer.RowGroups)))
+	for i, rg := range footer.RowGroups {
+		// the last row group is written by the call to Write
+		if i < len(footer.RowGroups)-1 {
+			assert.InDelta(t, 64*1024, rg.TotalByteSize, 8*1024, fmt.Sprint(i))
+		}
+	}
+
+	pageHeaders, err := getPageHeaders(rd, "code", footer)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, len(pageHeaders) > len(footer.RowGroups))
+	for _, ph := range pageHeaders {
+		assert.True(t, ph.UncompressedPageSize < 2048+64, fmt.Sprint(ph.UncompressedPageSize))
+	}
+
+	r, err := NewParquetReader(rd)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var i int
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		assert.Equal(t, *getExpected(input, i), p, fmt.Sprint(i))
+		i++
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, getLen(input), i)
+}
+
+func TestSpill(t *testing.T) {
+	input := getPeople(1000, 3000)
+
+	write := func(opts ...parquet.WriterOption) []byte {
+		return writePeople(t, input, append(opts, parquet.MaxPageSize(50))...)
+	}
+
+	dir := t.TempDir()
+	expected := write()
+	actual := write(parquet.SpillToTempFile(dir))
+	assert.Equal(t, expected, actual)
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(files))
+
+	actual = write(parquet.MaxBufferedBytes(16 * 1024))
+	footer, err := parquet.ReadMetaData(bytes.NewReader(actual))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, len(footer.RowGroups) > len(input), fmt.Sprint(len(footer.RowGroups)))
+
+	r, err := NewParquetReader(bytes.NewReader(actual))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var i int
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		assert.Equal(t, *getExpected(input, i), p, fmt.Sprint(i))
+		i++
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, getLen(input), i)
+}
+
+func TestValidation(t *testing.T) {
+	errNegative := errors.New("negative id")
+	var buf bytes.Buffer
+	w, err := NewParquetWriter(&buf, parquet.RejectInvalidUTF8, parquet.RejectNaN, parquet.MaxStringBytes(10), parquet.WithValidator(func(p Person) error {
+		if p.ID < 0 {
+			return errNegative
+		}
+		return nil
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	nan := float32(math.NaN())
+	testCases := []struct {
+		name   string
+		person Person
+		field  string
+		err    error
+	}{
+		{name: "valid", person: Person{Being: Being{ID: 1}, BFF: "bob"}},
+		{name: "utf8", person: Person{Being: Being{ID: 2}, BFF: "\xff"}, field: "bff", err: parquet.ErrInvalidUTF8},
+		{name: "optional utf8", person: Person{Being: Being{ID: 3}, Code: pstring("\xfe")}, field: "code", err: parquet.ErrInvalidUTF8},
+		{name: "too long", person: Person{Being: Being{ID: 4}, BFF: "abcdefghijk"}, field: "bff", err: parquet.ErrStringTooLong},
+		{name: "nan", person: Person{Being: Being{ID: 5}, Funkiness: nan}, field: "funkiness", err: parquet.ErrNaN},
+		{name: "optional nan", person: Person{Being: Being{ID: 6}, Lameness: &nan}, field: "lameness", err: parquet.ErrNaN},
+		{name: "validator", person: Person{Being: Being{ID: -1}}, err: errNegative},
+		{name: "valid again", person: Person{Being: Being{ID: 7}, BFF: "alice"}},
+	}
+
+	var expected []Person
+	for _, tc := range testCases {
+		err := w.Add(tc.person)
+		if tc.err == nil {
+			assert.NoError(t, err, tc.name)
+			expected = append(expected, tc.person)
+			continue
+		}
+
+		var re *parquet.RecordError
+		if assert.True(t, errors.As(err, &re), tc.name) {
+			assert.Equal(t, tc.field, re.Field, tc.name)
+		}
+		assert.True(t, errors.Is(err, tc.err), tc.name)
+	}
+
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+
+	r, err := NewParquetReader(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var actual []Person
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		actual = append(actual, p)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, expected, actual)
+}
+
+func TestMetadata(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     []parquet.WriterOption
+		expected parquet.FileMetadata
+	}{
+		{
+			name:     "default",
+			expected: parquet.FileMetadata{CreatedBy: parquet.CreatedBy},
+		},
+		{
+			name: "custom",
+			opts: []parquet.WriterOption{
+				parquet.WithCreatedBy("pipeline version 1.2.3"),
+				parquet.WithKeyValueMetadata(map[string]string{"pipeline": "hourly", "version": "1.2.3"}),
+			},
+			expected: parquet.FileMetadata{
+				CreatedBy: "pipeline version 1.2.3",
+				KeyValue:  map[string]string{"pipeline": "hourly", "version": "1.2.3"},
+			},
+		},
+		{
+			name:     "no created by",
+			opts:     []parquet.WriterOption{parquet.WithCreatedBy("")},
+			expected: parquet.FileMetadata{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewParquetWriter(&buf, tc.opts...)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.NoError(t, w.Add(Person{Being: Being{ID: 1}}))
+			assert.NoError(t, w.Write())
+			assert.NoError(t, w.Close())
+
+			r, err := NewParquetReader(bytes.NewReader(buf.Bytes()))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tc.expected, r.Metadata())
+		})
+	}
+}
+
+func TestOpenForAppend(t *testing.T) {
+	input := getPeople(100, 400)
+	pth := filepath.Join(t.TempDir(), "people.parquet")
+
+	write := func(open func(*os.File) (*ParquetWriter, error), rowgroups [][]Person) {
+		f, err := os.OpenFile(pth, os.O_CREATE|os.O_RDWR, 0600)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer f.Close()
+
+		w, err := open(f)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		for _, rowgroup := range rowgroups {
+			for _, p := range rowgroup {
+				assert.NoError(t, w.Add(p))
+			}
+			assert.NoError(t, w.Write())
+		}
+		assert.NoError(t, w.Close())
+	}
+
+	write(func(f *os.File) (*ParquetWriter, error) {
+		return NewParquetWriter(f, parquet.WithKeyValueMetadata(map[string]string{"hour": "1"}))
+	}, input[:2])
+
+	write(func(f *os.File) (*ParquetWriter, error) {
+		return OpenForAppend(f)
+	}, input[2:])
+
+	f, err := os.Open(pth)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	r, err := NewParquetReader(f)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 4, len(r.RowGroups()))
+	assert.Equal(t, int64(getLen(input)), r.Rows())
+	assert.Equal(t, map[string]string{"hour": "1"}, r.Metadata().KeyValue)
+
+	var i int
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		assert.Equal(t, *getExpected(input, i), p, fmt.Sprint(i))
+		i++
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, getLen(input), i)
+}
+
+// memFile is an in-memory io.ReadWriteSeeker without a Truncate method
+type memFile struct {
+	b   []byte
+	off int64
+}
+
+func (m *memFile) Read(p []byte) (int, error) {
+	if m.off >= int64(len(m.b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.b[m.off:])
+	m.off += int64(n)
+	return n, nil
+}
+
+func (m *memFile) Write(p []byte) (int, error) {
+	if end := m.off + int64(len(p)); end > int64(len(m.b)) {
+		m.b = append(m.b, make([]byte, end-int64(len(m.b)))...)
+	}
+	n := copy(m.b[m.off:], p)
+	m.off += int64(n)
+	return n, nil
+}
+
+func (m *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += m.off
+	case io.SeekEnd:
+		offset += int64(len(m.b))
+	}
+	m.off = offset
+	return offset, nil
+}
+
+func TestOpenForAppendWithoutTruncate(t *testing.T) {
+	input := getPeople(10, 20)
+
+	// the old footer has a longer created_by than the new one,
+	// so the new footer ends before the old one did
+	var f memFile
+	w, err := NewParquetWriter(&f, parquet.WithCreatedBy(strings.Repeat("x", 5000)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, p := range input[0] {
+		assert.NoError(t, w.Add(p))
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+	size := len(f.b)
+
+	w, err = OpenForAppend(&f)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, w.Add(input[1][0]))
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+	assert.Equal(t, size, len(f.b))
+
+	r, err := NewParquetReader(bytes.NewReader(f.b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, parquet.CreatedBy, r.Metadata().CreatedBy)
+	var out []Person
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		out = append(out, p)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, append(input[0][:len(input[0]):len(input[0])], input[1][0]), out)
+}
+
+func TestOpenForAppendSchemaMismatch(t *testing.T) {
+	// schema replaces the node at index i of personSchema with n
+	schema := func(i int, n parquet.Node) []parquet.Node {
+		out := append([]parquet.Node{}, personSchema...)
+		out[i] = n
+		return out
+	}
+
+	testCases := []struct {
+		name   string
+		schema []parquet.Node
+		err    string
+	}{
+		{
+			name:   "elements",
+			schema: []parquet.Node{parquet.Int32Node("x")},
+			err:    "schema doesn't match, expected 22 columns, got 1",
+		},
+		{
+			name:   "name",
+			schema: schema(1, parquet.StringNode("nickname")),
+			err:    "schema doesn't match, expected column name, got nickname",
+		},
+		{
+			name:   "type",
+			schema: schema(3, parquet.Int32Node("happiness")),
+			err:    "schema doesn't match for happiness: type expected INT64, got INT32",
+		},
+		{
+			name:   "repetition",
+			schema: schema(2, parquet.Int32Node("age")),
+			err:    "schema doesn't match for age: repetition expected OPTIONAL, got REQUIRED",
+		},
+		{
+			name:   "logical type",
+			schema: schema(10, parquet.Int32Node("birthday")),
+			err:    "schema doesn't match for birthday: logical type expected UNSIGNED, got NONE",
+		},
+		{
+			name: "group",
+			schema: schema(14, parquet.GroupNode("hobby",
+				parquet.StringNode("name"),
+				parquet.Int32Node("difficulty").Optional(),
+				parquet.GroupNode("skills",
+					parquet.StringNode("name"),
+					parquet.StringNode("difficulty"),
+				).Repeated(),
+			)),
+			err: "schema doesn't match for hobby.name: repetition expected OPTIONAL.REQUIRED, got REQUIRED.REQUIRED",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := parquet.NewSchema(tc.schema...)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			f, err := os.Create(filepath.Join(t.TempDir(), "other.parquet"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer f.Close()
+
+			w, err := parquet.NewDynamicWriter(f, s)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, w.Close())
+
+			_, err = OpenForAppend(f)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	input := getPeople(50, 600)
+
+	var srcs []io.ReadSeeker
+	for i := 0; i < len(input); i += 4 {
+		srcs = append(srcs, bytes.NewReader(writePeople(t, input[i:i+4], parquet.MaxPageSize(20))))
+	}
+
+	testCases := []struct {
+		name      string
+		merger    parquet.Merger
+		rowGroups int
+	}{
+		{name: "copy", rowGroups: len(input)},
+		{name: "coalesce", merger: parquet.Merger{CoalesceBytes: 1 << 30}, rowGroups: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if !assert.NoError(t, tc.merger.Merge(&buf, srcs...)) {
+				return
+			}
+
+			r, err := NewParquetReader(bytes.NewReader(buf.Bytes()))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tc.rowGroups, len(r.RowGroups()))
+			assert.Equal(t, int64(getLen(input)), r.Rows())
+
+			var i int
+			for r.Next() {
+				var p Person
+				r.Scan(&p)
+				assert.Equal(t, *getExpected(input, i), p, fmt.Sprint(i))
+				i++
+			}
+			assert.NoError(t, r.Error())
+			assert.Equal(t, getLen(input), i)
+		})
+	}
+
+	var other bytes.Buffer
+	m := parquet.New(parquet.Field{Name: "x", Path: []string{"x"}, Type: parquet.Int32Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}})
+	other.Write([]byte("PAR1"))
+	assert.NoError(t, m.Footer(&other))
+	other.Write([]byte("PAR1"))
+
+	assert.EqualError(t, parquet.Merge(io.Discard, srcs[0], bytes.NewReader(other.Bytes())), "unable to merge file 1, err: schema doesn't match, expected 22 columns, got 1")
+}
+
+func TestMergeCodecs(t *testing.T) {
+	input := getPeople(10, 60)
+
+	var srcs []io.ReadSeeker
+	for i, comp := range []parquet.WriterOption{parquet.Snappy, parquet.Gzip, parquet.Snappy} {
+		srcs = append(srcs, bytes.NewReader(writePeople(t, input[i*2:i*2+2], comp)))
+	}
+
+	var buf bytes.Buffer
+	m := parquet.Merger{CoalesceBytes: 1 << 30}
+	if !assert.NoError(t, m.Merge(&buf, srcs...)) {
+		return
+	}
+
+	// the row groups are only coalesced with the ones that have the same codec
+	fmd, err := parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var codecs []sch.CompressionCodec
+	for _, rg := range fmd.RowGroups {
+		codecs = append(codecs, rg.Columns[0].MetaData.Codec)
+	}
+	assert.Equal(t, []sch.CompressionCodec{sch.CompressionCodec_SNAPPY, sch.CompressionCodec_GZIP, sch.CompressionCodec_SNAPPY}, codecs)
+
+	r, err := NewParquetReader(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var i int
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		assert.Equal(t, *getExpected(input[:6], i), p, fmt.Sprint(i))
+		i++
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, getLen(input[:6]), i)
+}
+
+func TestMergeColumnOrders(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewParquetWriter(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, p := range getPeople(1, 10)[0] {
+		assert.NoError(t, w.Add(p))
+	}
+	assert.NoError(t, w.Close())
+
+	withOrders := func(n int) []byte {
+		return rewriteFooter(t, buf.Bytes(), func(fmd *sch.FileMetaData) {
+			for i := 0; i < n; i++ {
+				fmd.ColumnOrders = append(fmd.ColumnOrders, &sch.ColumnOrder{TYPE_ORDER: &sch.TypeDefinedOrder{}})
+			}
+		})
+	}
+
+	cols := 22
+	testCases := []struct {
+		name     string
+		srcs     [][]byte
+		expected int
+	}{
+		{name: "same", srcs: [][]byte{withOrders(cols), withOrders(cols)}, expected: cols},
+		{name: "one without", srcs: [][]byte{withOrders(cols), buf.Bytes()}},
+		{name: "first without", srcs: [][]byte{buf.Bytes(), withOrders(cols)}},
+		{name: "different", srcs: [][]byte{withOrders(cols), withOrders(cols - 1)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srcs := make([]io.ReadSeeker, len(tc.srcs))
+			for i, b := range tc.srcs {
+				srcs[i] = bytes.NewReader(b)
+			}
+
+			var merged bytes.Buffer
+			if !assert.NoError(t, parquet.Merge(&merged, srcs...)) {
+				return
+			}
+
+			fmd, err := parquet.ReadMetaData(bytes.NewReader(merged.Bytes()))
+			if assert.NoError(t, err) {
+				assert.Len(t, fmd.ColumnOrders, tc.expected)
+			}
+		})
+	}
+}
+
+// writeColumns writes a parquet file with a single row group that
+// only has the given fields, so the file's schema can be different
+// from the schema of T.
+func writeColumns[T any](t *testing.T, recs []T, ff ...parquet.Column[T]) []byte {
+	schema := make([]parquet.Field, len(ff))
+	for i, f := range ff {
+		schema[i] = f.Schema()
+	}
+
+	var buf bytes.Buffer
+	buf.Write([]byte("PAR1"))
+	m := parquet.New(schema...)
+	for _, rec := range recs {
+		m.NextDoc()
+		for _, f := range ff {
+			f.Add(rec)
+		}
+	}
+
+	for _, f := range ff {
+		assert.NoError(t, f.Write(&buf, m))
+	}
+	assert.NoError(t, m.Footer(&buf))
+	buf.Write([]byte("PAR1"))
+	return buf.Bytes()
+}
+
+func TestSchemaEvolution(t *testing.T) {
+	var people []Person
+	for i := 0; i < 100; i++ {
+		p := Person{
+			Being:     Being{ID: int32(i)},
+			Happiness: int64(-i),
+			Boldness:  float64(float32(i) / 3),
+		}
+		if i%2 == 0 {
+			p.Sadness = pint64(int64(i * 10))
+		}
+		people = append(people, p)
+	}
+
+	buf := writeColumns(t, people,
+		parquet.NewRequiredColumn(readID, writeID, []string{"id"}),
+		parquet.NewRequiredColumn(func(p Person) int32 { return p.ID * 2 }, nil, []string{"extra"}),
+		parquet.NewRequiredColumn(func(p Person) int32 { return int32(p.Happiness) }, nil, []string{"happiness"}),
+		parquet.NewRequiredColumn(func(p Person) float32 { return float32(p.Boldness) }, nil, []string{"boldness"}),
+		parquet.NewOptionalColumn(func(p Person, vals []int32, defs, reps []uint8) ([]int32, []uint8, []uint8) {
+			if p.Sadness == nil {
+				return vals, append(defs, 0), reps
+			}
+			return append(vals, int32(*p.Sadness)), append(defs, 1), reps
+		}, nil, []string{"sadness"}, []int{1}),
+	)
+
+	for _, opts := range [][]parquet.ReaderOption{nil, {parquet.StreamPages}} {
+		r, err := NewParquetReader(bytes.NewReader(buf), opts...)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var actual []Person
+		for r.Next() {
+			var p Person
+			r.Scan(&p)
+			actual = append(actual, p)
+		}
+		assert.NoError(t, r.Error())
+		assert.Equal(t, people, actual)
+	}
+
+	_, err := NewParquetReader(bytes.NewReader(buf), parquet.StrictSchema)
+	var se *parquet.SchemaError
+	if !assert.True(t, errors.As(err, &se)) {
+		return
+	}
+
+	diffs := map[string]parquet.SchemaDiffKind{}
+	for _, d := range se.Diffs {
+		diffs[d.Column] = d.Kind
+	}
+
+	assert.Equal(t, parquet.WidenedColumn, diffs["happiness"])
+	assert.Equal(t, parquet.WidenedColumn, diffs["boldness"])
+	assert.Equal(t, parquet.WidenedColumn, diffs["sadness"])
+	assert.Equal(t, parquet.UnknownColumn, diffs["extra"])
+	assert.Equal(t, parquet.MissingColumn, diffs["code"])
+	assert.Equal(t, parquet.MissingColumn, diffs["hobby.name"])
+	_, ok := diffs["id"]
+	assert.False(t, ok)
+
+	buf = writeColumns(t, people, parquet.NewRequiredColumn(func(p Person) int32 { return p.ID }, nil, []string{"bff"}))
+	_, err = NewParquetReader(bytes.NewReader(buf))
+	if assert.True(t, errors.As(err, &se)) {
+		assert.Equal(t, []parquet.SchemaDiff{{Kind: parquet.TypeMismatch, Column: "bff", Expected: "BYTE_ARRAY", Actual: "INT32"}}, se.Diffs)
+	}
+}
+
+func TestLogicalTypes(t *testing.T) {
+	var invoices []logical.Invoice
+	for i := 0; i < 10; i++ {
+		inv := logical.Invoice{
+			Id:       int64(i),
+			Total:    int32(i * 1050),
+			Customer: fmt.Sprintf("customer %d", i),
+		}
+		if i%2 == 0 {
+			inv.Due = pint32(int32(19000 + i))
+			inv.Lines = &logical.Lines{List: []logical.List{{Element: "a"}, {Element: "b"}}}
+		}
+		invoices = append(invoices, inv)
+	}
+
+	var buf bytes.Buffer
+	w, err := logical.NewParquetWriter(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, inv := range invoices {
+		assert.NoError(t, w.Add(inv))
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+
+	f, err := os.Open("cmd/parquetgen/dremel/testcases/logical/invoice.schema")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	elems, err := message.Parse(f)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	expected, err := message.Format(elems)
+	assert.NoError(t, err)
+
+	fmd, err := parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	actual, err := message.Format(fmd.Schema)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, int32(9), fmd.Schema[2].GetPrecision())
+	assert.Equal(t, int32(2), fmd.Schema[2].GetScale())
+
+	r, err := logical.NewParquetReader(bytes.NewReader(buf.Bytes()), parquet.StrictSchema)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out []logical.Invoice
+	for r.Next() {
+		var inv logical.Invoice
+		r.Scan(&inv)
+		out = append(out, inv)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, invoices, out)
+
+	// the logical types in the tags are also written with reflection
+	var rbuf bytes.Buffer
+	pw, err := parquet.NewWriter[logical.Invoice](&rbuf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, inv := range invoices {
+		assert.NoError(t, pw.Add(inv))
+	}
+	assert.NoError(t, pw.Write())
+	assert.NoError(t, pw.Close())
+	assert.Equal(t, buf.Bytes(), rbuf.Bytes())
+}
+
+func TestColumnMapping(t *testing.T) {
+	var members []fieldid.Member
+	for i := 0; i < 10; i++ {
+		m := fieldid.Member{
+			ID:        int32(i),
+			Happiness: int64(i * 2),
+			Sleepy:    i%3 == 0,
+		}
+		if i%2 == 0 {
+			m.Sadness = pint64(int64(i * 10))
+		}
+		members = append(members, m)
+	}
+
+	buf := writeColumns(t, members,
+		parquet.NewRequiredColumn(func(m fieldid.Member) int32 { return m.ID }, nil, []string{"id"}),
+		parquet.NewRequiredColumn(func(m fieldid.Member) int64 { return m.Happiness }, nil, []string{"joy"}, parquet.RequiredFieldID(2)),
+		parquet.NewRequiredColumn(func(m fieldid.Member) int64 { return -1 }, nil, []string{"happiness"}),
+		parquet.NewOptionalColumn(func(m fieldid.Member, vals []int64, defs, reps []uint8) ([]int64, []uint8, []uint8) {
+			if m.Sadness == nil {
+				return vals, append(defs, 0), reps
+			}
+			return append(vals, *m.Sadness), append(defs, 1), reps
+		}, nil, []string{"SADNESS"}, []int{1}),
+		parquet.NewRequiredColumn(func(m fieldid.Member) bool { return m.Sleepy }, nil, []string{"sleepy"}),
+	)
+
+	fmd, err := parquet.ReadMetaData(bytes.NewReader(buf))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "joy", fmd.Schema[2].Name)
+		assert.Equal(t, int32(2), fmd.Schema[2].GetFieldID())
+		assert.False(t, fmd.Schema[3].IsSetFieldID())
+	}
+
+	read := func(opts ...parquet.ReaderOption) []fieldid.Member {
+		r, err := fieldid.NewParquetReader(bytes.NewReader(buf), opts...)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+
+		var out []fieldid.Member
+		for r.Next() {
+			var m fieldid.Member
+			r.Scan(&m)
+			out = append(out, m)
+		}
+		assert.NoError(t, r.Error())
+		return out
+	}
+
+	var expected []fieldid.Member
+	for _, m := range members {
+		expected = append(expected, fieldid.Member{ID: m.ID, Happiness: m.Happiness})
+	}
+	assert.Equal(t, expected, read())
+	assert.Equal(t, members, read(parquet.CaseInsensitive))
+	assert.Equal(t, members, read(parquet.CaseInsensitive, parquet.StreamPages))
+
+	_, err = fieldid.NewParquetReader(bytes.NewReader(buf), parquet.StrictSchema)
+	var se *parquet.SchemaError
+	if assert.True(t, errors.As(err, &se)) {
+		diffs := map[string]parquet.SchemaDiffKind{}
+		for _, d := range se.Diffs {
+			diffs[d.Column] = d.Kind
+		}
+
+		_, ok := diffs["joy"]
+		assert.False(t, ok)
+		assert.Equal(t, parquet.UnknownColumn, diffs["happiness"])
+		assert.Equal(t, parquet.UnknownColumn, diffs["SADNESS"])
+		assert.Equal(t, parquet.MissingColumn, diffs["sadness"])
+	}
+
+	var out bytes.Buffer
+	w, err := fieldid.NewParquetWriter(&out)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, w.Add(members[0]))
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+
+	fmd, err = parquet.ReadMetaData(bytes.NewReader(out.Bytes()))
+	if assert.NoError(t, err) {
+		ids := map[string]int32{}
+		for _, se := range fmd.Schema {
+			if se.IsSetFieldID() {
+				ids[se.Name] = se.GetFieldID()
+			}
+		}
+		assert.Equal(t, map[string]int32{"happiness": 2, "sadness": 3}, ids)
+	}
+}
+
+func TestLegacySchema(t *testing.T) {
+	people := []Person{
+		{Being: Being{ID: 1}, Hobby: &Hobby{Name: "golf", Skills: []Skill{{Name: "putting"}}}, Friends: []Being{{ID: 2, Name: "b"}}},
+		{Being: Being{ID: 3}},
+	}
+
+	legacy := legacyFile(t, writePeople(t, [][]Person{people}))
+	r, err := NewParquetReader(bytes.NewReader(legacy), parquet.StrictSchema)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var actual []Person
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		actual = append(actual, p)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, people, actual)
+}
+
+func TestSchemaNumChildren(t *testing.T) {
+	b := writePeople(t, [][]Person{{{Being: Being{ID: 1}}}})
+	fmd, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the num_children of a group is the number of its direct children
+	children := map[string]int32{}
+	for _, se := range fmd.Schema {
+		if se.GetNumChildren() > 0 {
+			children[se.Name] = se.GetNumChildren()
+		}
+	}
+	assert.Equal(t, int32(3), children["hobby"])
+	assert.Equal(t, int32(2), children["skills"])
+	assert.Equal(t, int32(3), children["friends"])
+	assert.Equal(t, fmd.Schema, parquet.NormalizeSchema(fmd.Schema))
+}
+
+func TestAppendLegacySchema(t *testing.T) {
+	input := getPeople(10, 30)
+
+	legacy := legacyFile(t, writePeople(t, input[:1]))
+
+	pth := filepath.Join(t.TempDir(), "legacy.parquet")
+	if !assert.NoError(t, os.WriteFile(pth, legacy, 0600)) {
+		return
+	}
 
-				err = w.Close()
-				assert.Nil(t, err, tc.name)
+	f, err := os.OpenFile(pth, os.O_RDWR, 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
 
-				r, err := NewParquetReader(bytes.NewReader(buf.Bytes()))
-				if !assert.NoError(t, err) {
-					return
-				}
+	w, err := OpenForAppend(f)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, p := range input[1] {
+		assert.NoError(t, w.Add(p))
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
 
-				expected := tc.expected
-				if expected == nil {
-					expected = tc.input
+	read := func(r io.ReadSeeker) {
+		pr, err := NewParquetReader(r)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var i int
+		for pr.Next() {
+			var p Person
+			pr.Scan(&p)
+			assert.Equal(t, *getExpected(input[:2], i), p, fmt.Sprint(i))
+			i++
+		}
+		assert.NoError(t, pr.Error())
+		assert.Equal(t, getLen(input[:2]), i)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if assert.NoError(t, err) {
+		read(f)
+	}
+
+	// a legacy file can also be merged with one that only
+	// counts the direct children of its groups
+	cur := directFile(t, writePeople(t, input[1:2]))
+	var merged bytes.Buffer
+	if assert.NoError(t, parquet.Merge(&merged, bytes.NewReader(legacy), bytes.NewReader(cur))) {
+		read(bytes.NewReader(merged.Bytes()))
+	}
+}
+
+// legacyFile rewrites the footer of the parquet file in b with the
+// legacy num_children: the num_children of a group is the number of
+// leaves below it and every group is counted as a child of the root.
+func legacyFile(t *testing.T, b []byte) []byte {
+	return rewriteFooter(t, b, func(fmd *sch.FileMetaData) {
+		fmd.Schema = parquet.NormalizeSchema(fmd.Schema)
+		legacySchema(fmd.Schema)
+	})
+}
+
+// directFile rewrites the footer of the parquet file in b so that the
+// num_children of a group is the number of its direct children.
+func directFile(t *testing.T, b []byte) []byte {
+	return rewriteFooter(t, b, func(fmd *sch.FileMetaData) {
+		fmd.Schema = parquet.NormalizeSchema(fmd.Schema)
+	})
+}
+
+// legacySchema changes the num_children of elems, which counts the
+// direct children of each group, to the legacy layout (see legacyFile).
+func legacySchema(elems []*sch.SchemaElement) {
+	var groups int32
+	var walk func(i int) (int, int32)
+	walk = func(i int) (int, int32) {
+		se := elems[i]
+		n := int(se.GetNumChildren())
+		i++
+		if n == 0 {
+			return i, 1
+		}
+
+		var leaves int32
+		for j := 0; j < n; j++ {
+			var l int32
+			i, l = walk(i)
+			leaves += l
+		}
+		groups++
+		se.NumChildren = &leaves
+		return i, leaves
+	}
+
+	var children int32
+	i := 1
+	for j := 0; j < int(elems[0].GetNumChildren()); j++ {
+		if elems[i].GetNumChildren() == 0 {
+			children++
+		}
+		i, _ = walk(i)
+	}
+	children += groups
+	elems[0].NumChildren = &children
+}
+
+// rewriteFooter changes the footer of the parquet file in b with f
+func rewriteFooter(t *testing.T, b []byte, f func(*sch.FileMetaData)) []byte {
+	fmd, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	f(fmd)
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	footer, err := ts.Write(context.TODO(), fmd)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	size := binary.LittleEndian.Uint32(b[len(b)-8:])
+	out := append([]byte{}, b[:len(b)-8-int(size)]...)
+	out = append(out, footer...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(footer)))
+	return append(out, "PAR1"...)
+}
+
+func TestReflection(t *testing.T) {
+	input := append(getPeople(10, 35), []Person{
+		{Friends: []Being{{ID: 1, Age: pint32(10)}}},
+		{Code: pstring("c"), Keen: pbool(false)},
+		{Friends: []Being{{ID: 2, Age: pint32(12)}, {ID: 3}, {ID: 4, Name: "d", Age: pint32(14)}}},
+		{
+			Hobby: &Hobby{
+				Name:       "napping",
+				Difficulty: pint32(10),
+				Skills: []Skill{
+					{Name: "meditation", Difficulty: "very"},
+					{Name: "calmness", Difficulty: "so-so"},
+				},
+			},
+		},
+		{Hobby: &Hobby{Name: "sleeping"}},
+	})
+
+	for _, comp := range compressionCases {
+		t.Run(comp, func(t *testing.T) {
+			var expected bytes.Buffer
+			w, err := NewParquetWriter(&expected, parquet.MaxPageSize(3), compressionTest[comp])
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			opts := map[string]parquet.WriterOption{
+				"uncompressed": parquet.Uncompressed,
+				"snappy":       parquet.Snappy,
+				"gzip":         parquet.Gzip,
+			}
+
+			var buf bytes.Buffer
+			pw, err := parquet.NewWriter[Person](&buf, parquet.MaxPageSize(3), opts[comp])
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, rg := range input {
+				for _, p := range rg {
+					assert.NoError(t, w.Add(p))
+					assert.NoError(t, pw.Add(p))
 				}
+				assert.NoError(t, w.Write())
+				assert.NoError(t, pw.Write())
+			}
+			assert.NoError(t, w.Close())
+			assert.NoError(t, pw.Close())
 
-				if !assert.Equal(t, getLen(expected), int(r.Rows()), tc.name) {
+			// the file is the same as the one written by the generated code
+			assert.Equal(t, expected.Bytes(), buf.Bytes())
+
+			for _, opts := range [][]parquet.ReaderOption{nil, {parquet.StreamPages}, {parquet.WithConcurrency(3)}} {
+				r, err := parquet.NewReader[Person](bytes.NewReader(buf.Bytes()), opts...)
+				if !assert.NoError(t, err) {
 					return
 				}
 
@@ -489,39 +2025,541 @@ func TestParquet(t *testing.T) {
 				for r.Next() {
 					var p Person
 					r.Scan(&p)
-					exp := getExpected(expected, i)
-					assert.Equal(t, *exp, p, fmt.Sprintf("%s-%d", tc.name, i))
+					assert.Equal(t, *getExpected(input, i), p, i)
 					i++
 				}
+				assert.NoError(t, r.Error())
+				assert.Equal(t, getLen(input), i)
+			}
 
-				assert.Nil(t, r.Error(), tc.name)
-				assert.Equal(t, getLen(expected), i, tc.name)
-			})
+			r, err := parquet.NewReader[Person](bytes.NewReader(buf.Bytes()), parquet.StreamPages)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if assert.NoError(t, r.SeekToRow(23)) {
+				var p Person
+				assert.True(t, r.Next())
+				r.Scan(&p)
+				assert.Equal(t, *getExpected(input, 23), p)
+			}
+		})
+	}
+}
+
+func TestReflectionErrors(t *testing.T) {
+	type unsupported struct {
+		ID   int32 `parquet:"id"`
+		Tags map[string]string
+	}
+
+	type badTag struct {
+		ID int32 `parquet:"id,required"`
+	}
+
+	type badLogical struct {
+		Total int32 `parquet:"total,logical=DECIMAL(9)"`
+	}
+
+	type nothing struct {
+		id int32
+	}
+
+	var buf bytes.Buffer
+	_, err := parquet.NewWriter[int](&buf)
+	assert.EqualError(t, err, "int is not a struct")
+
+	_, err = parquet.NewWriter[unsupported](&buf)
+	assert.EqualError(t, err, "unsupported type map[string]string of field Tags")
+
+	_, err = parquet.NewWriter[badTag](&buf)
+	assert.EqualError(t, err, "invalid tag on field ID, err: unknown option required")
+
+	_, err = parquet.NewWriter[badLogical](&buf)
+	assert.EqualError(t, err, "invalid tag on field Total, err: invalid logical type DECIMAL(9): expected 2 arguments, found 1")
+
+	_, err = parquet.NewWriter[nothing](&buf)
+	assert.EqualError(t, err, "parquet_test.nothing has no columns")
+
+	_, err = parquet.NewReader[unsupported](bytes.NewReader(nil))
+	assert.EqualError(t, err, "unsupported type map[string]string of field Tags")
+
+	w, err := parquet.NewWriter[Person](&buf, parquet.RejectNaN, parquet.WithValidator(func(p Person) error {
+		if p.ID < 0 {
+			return errors.New("negative id")
 		}
+		return nil
+	}))
+	if !assert.NoError(t, err) {
+		return
 	}
+
+	var re *parquet.RecordError
+	assert.True(t, errors.As(w.Add(Person{Being: Being{ID: -1}}), &re))
+	assert.True(t, errors.As(w.Add(Person{Boldness: math.NaN()}), &re))
+	assert.NoError(t, w.Add(Person{Being: Being{ID: 1}}))
+	assert.NoError(t, w.Close())
 }
 
-func TestPageHeaders(t *testing.T) {
+func TestOpenDynamic(t *testing.T) {
+	people := []Person{
+		{
+			Being:     Being{ID: 1, Name: "a", Age: pint32(10)},
+			Happiness: 2,
+			Code:      pstring("c"),
+			Keen:      pbool(false),
+			Birthday:  3,
+			Hobby: &Hobby{
+				Name:       "napping",
+				Difficulty: pint32(10),
+				Skills: []Skill{
+					{Name: "meditation", Difficulty: "very"},
+					{Name: "calmness", Difficulty: "so-so"},
+				},
+			},
+			Friends: []Being{{ID: 2, Age: pint32(12)}, {ID: 3, Name: "d"}},
+			Sleepy:  true,
+		},
+		{
+			Being:       Being{ID: 4},
+			Sadness:     pint64(5),
+			Anniversary: puint64(6),
+			Hobby:       &Hobby{Name: "sleeping"},
+		},
+	}
+
 	var buf bytes.Buffer
-	w, err := NewParquetWriter(&buf, MaxPageSize(2))
+	w, err := NewParquetWriter(&buf, parquet.MaxPageSize(1))
 	if !assert.NoError(t, err) {
 		return
 	}
 
-	docs := [][]Person{
-		{{}, {}, {}, {}},
-		{{}, {}, {}, {}},
+	for _, p := range people {
+		assert.NoError(t, w.Add(p))
 	}
-	for _, rowgroup := range docs {
-		for _, p := range rowgroup {
-			w.Add(p)
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Add(Person{Being: Being{ID: 7}}))
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+
+	r, err := parquet.OpenDynamic(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, int64(3), r.Rows())
+	assert.Equal(t, "hobby.skills.difficulty", r.Columns()[len(r.Columns())-5])
+
+	expected := []map[string]any{
+		{
+			"id": int32(1), "name": "a", "age": int32(10), "happiness": int64(2), "sadness": nil,
+			"code": "c", "funkiness": float32(0), "boldness": float64(0), "lameness": nil, "keen": false,
+			"birthday": uint32(3), "anniversary": nil, "bff": "", "hungry": false,
+			"hobby": map[string]any{
+				"name":       "napping",
+				"difficulty": int32(10),
+				"skills": []any{
+					map[string]any{"name": "meditation", "difficulty": "very"},
+					map[string]any{"name": "calmness", "difficulty": "so-so"},
+				},
+			},
+			"friends": []any{
+				map[string]any{"id": int32(2), "name": "", "age": int32(12)},
+				map[string]any{"id": int32(3), "name": "d", "age": nil},
+			},
+			"Sleepy": true,
+		},
+		{
+			"id": int32(4), "name": "", "age": nil, "happiness": int64(0), "sadness": int64(5),
+			"code": nil, "funkiness": float32(0), "boldness": float64(0), "lameness": nil, "keen": nil,
+			"birthday": uint32(0), "anniversary": uint64(6), "bff": "", "hungry": false,
+			"hobby":   map[string]any{"name": "sleeping", "difficulty": nil, "skills": nil},
+			"friends": nil,
+			"Sleepy":  false,
+		},
+		{
+			"id": int32(7), "name": "", "age": nil, "happiness": int64(0), "sadness": nil,
+			"code": nil, "funkiness": float32(0), "boldness": float64(0), "lameness": nil, "keen": nil,
+			"birthday": uint32(0), "anniversary": nil, "bff": "", "hungry": false,
+			"hobby": nil, "friends": nil, "Sleepy": false,
+		},
+	}
+
+	var rows []parquet.Row
+	for r.Next() {
+		assert.Equal(t, expected[len(rows)], r.Map())
+		rows = append(rows, r.Row())
+	}
+	assert.NoError(t, r.Error())
+	assert.Len(t, rows, 3)
+
+	row := rows[0]
+	id, ok := row.Int32("id")
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), id)
+
+	name, ok := row.String("hobby.name")
+	assert.True(t, ok)
+	assert.Equal(t, "napping", name)
+
+	skills, ok := row.List("hobby.skills")
+	assert.True(t, ok)
+	assert.Len(t, skills, 2)
+
+	hobby, ok := row.Group("hobby")
+	assert.True(t, ok)
+	d, ok := hobby.Int32("difficulty")
+	assert.True(t, ok)
+	assert.Equal(t, int32(10), d)
+
+	_, ok = row.Int64("sadness")
+	assert.False(t, ok)
+	_, ok = row.Int64("id")
+	assert.False(t, ok)
+	_, ok = row.Bool("nope.nope")
+	assert.False(t, ok)
+	_, ok = rows[2].String("hobby.name")
+	assert.False(t, ok)
+}
+
+var personSchema = []parquet.Node{
+	parquet.Int32Node("id"),
+	parquet.StringNode("name"),
+	parquet.Int32Node("age").Optional(),
+	parquet.Int64Node("happiness"),
+	parquet.Int64Node("sadness").Optional(),
+	parquet.StringNode("code").Optional(),
+	parquet.Float32Node("funkiness"),
+	parquet.Float64Node("boldness"),
+	parquet.Float32Node("lameness").Optional(),
+	parquet.BoolNode("keen").Optional(),
+	parquet.Uint32Node("birthday"),
+	parquet.Uint64Node("anniversary").Optional(),
+	parquet.StringNode("bff"),
+	parquet.BoolNode("hungry"),
+	parquet.GroupNode("hobby",
+		parquet.StringNode("name"),
+		parquet.Int32Node("difficulty").Optional(),
+		parquet.GroupNode("skills",
+			parquet.StringNode("name"),
+			parquet.StringNode("difficulty"),
+		).Repeated(),
+	).Optional(),
+	parquet.GroupNode("friends",
+		parquet.Int32Node("id"),
+		parquet.StringNode("name"),
+		parquet.Int32Node("age").Optional(),
+	).Repeated(),
+	parquet.BoolNode("Sleepy"),
+}
+
+// personRow turns p into a row of personSchema.  The groups are []any
+// and the optional values are pointers.
+func personRow(p Person) map[string]any {
+	var hobby []any
+	if p.Hobby != nil {
+		var skills []any
+		for _, s := range p.Hobby.Skills {
+			skills = append(skills, []any{s.Name, s.Difficulty})
 		}
-		assert.NoError(t, w.Write())
+		hobby = []any{p.Hobby.Name, p.Hobby.Difficulty, skills}
+	}
+
+	var friends []any
+	for _, f := range p.Friends {
+		friends = append(friends, map[string]any{"id": f.ID, "name": f.Name, "age": f.Age})
+	}
+
+	row := map[string]any{
+		"id": p.ID, "name": p.Name, "age": p.Age, "happiness": p.Happiness, "sadness": p.Sadness,
+		"code": p.Code, "funkiness": p.Funkiness, "boldness": p.Boldness, "lameness": p.Lameness,
+		"keen": p.Keen, "birthday": p.Birthday, "anniversary": p.Anniversary, "bff": p.BFF,
+		"hungry": p.Hungry, "friends": friends, "Sleepy": p.Sleepy,
+	}
+
+	if hobby != nil {
+		row["hobby"] = hobby
+	}
+	return row
+}
+
+func TestDynamicWriter(t *testing.T) {
+	s, err := parquet.NewSchema(personSchema...)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var expected []parquet.Field
+	for _, f := range (PersonCodec{}).Fields(sch.CompressionCodec_UNCOMPRESSED) {
+		expected = append(expected, f.Schema())
+	}
+
+	fields := s.Fields()
+	if assert.Len(t, fields, len(expected)) {
+		for i, f := range fields {
+			assert.Equal(t, expected[i].Name, f.Name)
+			assert.Equal(t, expected[i].Path, f.Path)
+			assert.Equal(t, expected[i].ID, f.ID)
+		}
+	}
+
+	input := append(getPeople(10, 25), []Person{
+		{Friends: []Being{{ID: 2, Age: pint32(12)}, {ID: 3}, {ID: 4, Name: "d", Age: pint32(14)}}},
+		{
+			Hobby: &Hobby{
+				Name:       "napping",
+				Difficulty: pint32(10),
+				Skills: []Skill{
+					{Name: "meditation", Difficulty: "very"},
+					{Name: "calmness", Difficulty: "so-so"},
+				},
+			},
+		},
+		{Hobby: &Hobby{Name: "sleeping"}},
+	})
+
+	for _, comp := range compressionCases {
+		t.Run(comp, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewParquetWriter(&buf, parquet.MaxPageSize(4), compressionTest[comp])
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			opts := map[string]parquet.WriterOption{
+				"uncompressed": parquet.Uncompressed,
+				"snappy":       parquet.Snappy,
+				"gzip":         parquet.Gzip,
+			}
+
+			var dbuf bytes.Buffer
+			dw, err := parquet.NewDynamicWriter(&dbuf, s, parquet.MaxPageSize(4), opts[comp])
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, rg := range input {
+				for _, p := range rg {
+					assert.NoError(t, w.Add(p))
+					assert.NoError(t, dw.Add(personRow(p)))
+				}
+				assert.NoError(t, w.Write())
+				assert.NoError(t, dw.Write())
+			}
+			assert.NoError(t, w.Close())
+			assert.NoError(t, dw.Close())
+
+			// the file is the same as the one written by the generated code
+			assert.Equal(t, buf.Bytes(), dbuf.Bytes())
+		})
+	}
+}
+
+func TestDynamicWriterAnnotations(t *testing.T) {
+	s, err := parquet.NewSchema(
+		parquet.Int64Node("id").WithID(1),
+		parquet.Int32Node("total").WithAnnotation(annotation(t, "DECIMAL(9,2)")),
+		parquet.Int32Node("due").Optional().WithAnnotation(annotation(t, "DATE")),
+		parquet.StringNode("customer").WithAnnotation(annotation(t, "STRING")),
+		parquet.GroupNode("lines",
+			parquet.GroupNode("list", parquet.StringNode("element").WithAnnotation(annotation(t, "STRING"))).Repeated(),
+		).Optional().WithAnnotation(annotation(t, "LIST")),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	w, err := logical.NewParquetWriter(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var dbuf bytes.Buffer
+	dw, err := parquet.NewDynamicWriter(&dbuf, s)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for i := 0; i < 5; i++ {
+		inv := logical.Invoice{Id: int64(i), Total: int32(i * 100), Customer: fmt.Sprintf("customer %d", i)}
+		row := parquet.Row{"id": inv.Id, "total": inv.Total, "customer": inv.Customer}
+		if i%2 == 0 {
+			inv.Due = pint32(int32(i))
+			inv.Lines = &logical.Lines{List: []logical.List{{Element: "a"}}}
+			row["due"] = inv.Due
+			row["lines"] = map[string]any{"list": []any{map[string]any{"element": "a"}}}
+		}
+		assert.NoError(t, w.Add(inv))
+		assert.NoError(t, dw.Add(row))
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, dw.Write())
+	assert.NoError(t, w.Close())
+	assert.NoError(t, dw.Close())
+
+	// the footer has the same logical types as the one written by the generated code
+	assert.Equal(t, buf.Bytes(), dbuf.Bytes())
+}
+
+func TestDynamicWriterRows(t *testing.T) {
+	s, err := parquet.NewSchema(
+		parquet.Int64Node("id"),
+		parquet.StringNode("name").Optional(),
+		parquet.Float64Node("scores").Repeated(),
+		parquet.GroupNode("tags", parquet.StringNode("key"), parquet.StringNode("value").Optional()).Repeated(),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	w, err := parquet.NewDynamicWriter(&buf, s, parquet.RejectNaN)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, w.Add([]any{1, "a", []float64{1.5, 2}, []any{[]any{"k", "v"}, map[string]any{"key": "j"}}}))
+	assert.NoError(t, w.Add(map[string]any{"id": int32(2), "scores": []any{}}))
+	assert.NoError(t, w.Add(parquet.Row{"id": uint8(3), "name": pstring("c"), "tags": []map[string]any{{"key": "x", "value": nil}}}))
+
+	errs := map[string]any{
+		"invalid value for id, err: required value is null":                    map[string]any{"name": "a"},
+		"invalid value for nope, err: not in the schema":                       map[string]any{"id": 1, "nope": 2},
+		"invalid value for name, err: int can't be written to a string column": map[string]any{"id": 1, "name": 2},
+		"invalid value for id, err: 1.5 overflows a int64 column":              map[string]any{"id": 1.5},
+		"invalid value for scores, err: float64 is not a slice":                map[string]any{"id": 1, "scores": 1.5},
+		"invalid value for scores, err: element 1 is null":                     map[string]any{"id": 1, "scores": []any{1.5, nil}},
+		"invalid value for scores, err: float is NaN":                          map[string]any{"id": 1, "scores": []any{math.NaN()}},
+		"invalid value for tags.key, err: required value is null":              map[string]any{"id": 1, "tags": []any{[]any{nil, "v"}}},
+		"invalid value for tags, err: 1 values, expected 2":                    map[string]any{"id": 1, "tags": []any{[]any{"k"}}},
+		"invalid record, err: 2 values, expected 4":                            []any{1, 2},
+		"invalid record, err: string is not a map[string]any or []any":         "nope",
 	}
 
+	for msg, row := range errs {
+		err := w.Add(row)
+		var re *parquet.RecordError
+		assert.True(t, errors.As(err, &re), msg)
+		assert.EqualError(t, err, msg)
+	}
+	assert.NoError(t, w.Write())
 	assert.NoError(t, w.Close())
 
-	rd := bytes.NewReader(buf.Bytes())
+	r, err := parquet.OpenDynamic(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, s.Fields()[3].Path, []string{"tags", "key"})
+	assert.Equal(t, len(s.Nodes()), len(r.Schema().Nodes()))
+
+	var rows []map[string]any
+	for r.Next() {
+		rows = append(rows, r.Map())
+	}
+	assert.NoError(t, r.Error())
+
+	assert.Equal(t, []map[string]any{
+		{"id": int64(1), "name": "a", "scores": []any{1.5, 2.0}, "tags": []any{map[string]any{"key": "k", "value": "v"}, map[string]any{"key": "j", "value": nil}}},
+		{"id": int64(2), "name": nil, "scores": nil, "tags": nil},
+		{"id": int64(3), "name": "c", "scores": nil, "tags": []any{map[string]any{"key": "x", "value": nil}}},
+	}, rows)
+}
+
+func TestNewSchema(t *testing.T) {
+	_, err := parquet.NewSchema()
+	assert.EqualError(t, err, "schema has no columns")
+
+	_, err = parquet.NewSchema(parquet.Int32Node("id"), parquet.GroupNode("a", parquet.Int32Node("x"), parquet.BoolNode("x")))
+	assert.EqualError(t, err, "a.x is defined more than once")
+
+	_, err = parquet.NewSchema(parquet.GroupNode("a").Optional())
+	assert.EqualError(t, err, "group a has no columns")
+
+	_, err = parquet.NewSchema(parquet.Int32Node("a.b"))
+	assert.EqualError(t, err, `invalid name "a.b" in ""`)
+}
+
+func TestSchemaValidation(t *testing.T) {
+	people := []Person{{Being: Being{ID: 1}}, {Being: Being{ID: 2}}}
+
+	testCases := []struct {
+		name     string
+		field    parquet.Column[Person]
+		expected parquet.SchemaDiff
+		msg      string
+	}{
+		{
+			name:     "physical type",
+			field:    parquet.NewRequiredColumn(func(p Person) float64 { return float64(p.ID) }, nil, []string{"id"}),
+			expected: parquet.SchemaDiff{Kind: parquet.TypeMismatch, Column: "id", Expected: "INT32", Actual: "DOUBLE"},
+			msg:      "schema doesn't match: column id is DOUBLE in the file, expected INT32",
+		},
+		{
+			name:     "repetition",
+			field:    parquet.NewRequiredColumn(func(p Person) int32 { return p.ID }, nil, []string{"age"}),
+			expected: parquet.SchemaDiff{Kind: parquet.RepetitionMismatch, Column: "age", Expected: "OPTIONAL", Actual: "REQUIRED"},
+			msg:      "schema doesn't match: column age has repetition REQUIRED in the file, expected OPTIONAL",
+		},
+		{
+			name:     "logical type",
+			field:    parquet.NewRequiredColumn(func(p Person) int32 { return p.ID }, nil, []string{"birthday"}),
+			expected: parquet.SchemaDiff{Kind: parquet.LogicalTypeMismatch, Column: "birthday", Expected: "UNSIGNED", Actual: "NONE"},
+			msg:      "schema doesn't match: column birthday has logical type NONE in the file, expected UNSIGNED",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := writeColumns(t, people, tc.field)
+			_, err := NewParquetReader(bytes.NewReader(buf))
+			var se *parquet.SchemaError
+			if !assert.True(t, errors.As(err, &se)) {
+				return
+			}
+			assert.Equal(t, []parquet.SchemaDiff{tc.expected}, se.Diffs)
+			assert.Equal(t, tc.msg, err.Error())
+		})
+	}
+}
+
+func TestSeekPage(t *testing.T) {
+	oi := &sch.OffsetIndex{
+		PageLocations: []*sch.PageLocation{
+			{Offset: 100, CompressedPageSize: 50, FirstRowIndex: 0},
+			{Offset: 150, CompressedPageSize: 50, FirstRowIndex: 10},
+			{Offset: 200, CompressedPageSize: 50, FirstRowIndex: 20},
+		},
+	}
+
+	testCases := []struct {
+		row    int64
+		offset int64
+		size   int
+		skip   int64
+	}{
+		{row: 0, offset: 100, size: 150, skip: 0},
+		{row: 9, offset: 100, size: 150, skip: 9},
+		{row: 10, offset: 150, size: 100, skip: 0},
+		{row: 25, offset: 200, size: 50, skip: 5},
+	}
+
+	for _, tc := range testCases {
+		pg := parquet.Page{Offset: 100, Size: 150}
+		skip := parquet.SeekPage(&pg, oi, tc.row)
+		assert.Equal(t, tc.skip, skip, fmt.Sprint(tc.row))
+		assert.Equal(t, tc.offset, pg.Offset, fmt.Sprint(tc.row))
+		assert.Equal(t, tc.size, pg.Size, fmt.Sprint(tc.row))
+	}
+}
+
+func TestPageHeaders(t *testing.T) {
+	docs := [][]Person{
+		{{}, {}, {}, {}},
+		{{}, {}, {}, {}},
+	}
+	rd := bytes.NewReader(writePeople(t, docs, parquet.MaxPageSize(2)))
 	footer, err := parquet.ReadMetaData(rd)
 	if !assert.NoError(t, err) {
 		return
@@ -716,20 +2754,9 @@ func TestStats(t *testing.T) {
 				if tc.pageSize == 0 {
 					tc.pageSize = 100
 				}
-				var buf bytes.Buffer
-				w, err := NewParquetWriter(&buf, MaxPageSize(tc.pageSize), compressionTest[comp])
-				assert.Nil(t, err, tc.name)
-				for _, rowgroup := range tc.input {
-					for _, p := range rowgroup {
-						w.Add(p)
-					}
-					assert.Nil(t, w.Write(), tc.name)
-				}
-
-				err = w.Close()
-				assert.Nil(t, err, tc.name)
+				b := writePeople(t, tc.input, parquet.MaxPageSize(tc.pageSize), compressionTest[comp])
 
-				r := bytes.NewReader(buf.Bytes())
+				r := bytes.NewReader(b)
 				footer, err := parquet.ReadMetaData(r)
 				if !assert.NoError(t, err) {
 					return
@@ -775,10 +2802,10 @@ func getPageHeaders(r io.ReadSeeker, name string, footer *sch.FileMetaData) ([]s
 	return out, nil
 }
 
-var compressionTest = map[string]func(*ParquetWriter) error{
-	"uncompressed": Uncompressed,
-	"snappy":       Snappy,
-	"gzip":         Gzip,
+var compressionTest = map[string]parquet.WriterOption{
+	"uncompressed": parquet.Uncompressed,
+	"snappy":       parquet.Snappy,
+	"gzip":         parquet.Gzip,
 }
 
 func getLen(peeps [][]Person) int {
@@ -854,6 +2881,26 @@ func randString(n int) *string {
 	return &s
 }
 
+// writePeople writes each of the row groups in input
+// to a parquet file with opts and returns the file.
+func writePeople(t *testing.T, input [][]Person, opts ...parquet.WriterOption) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewParquetWriter(&buf, opts...)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+
+	for _, rowgroup := range input {
+		for _, p := range rowgroup {
+			assert.NoError(t, w.Add(p))
+		}
+		assert.NoError(t, w.Write())
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
 func getPeople(rgSize, n int) [][]Person {
 	var out [][]Person
 	var rg []Person
@@ -921,7 +2968,7 @@ func newPerson(i int) Person {
 
 func BenchmarkRead(b *testing.B) {
 	var buf bytes.Buffer
-	w, err := NewParquetWriter(&buf, MaxPageSize(10000))
+	w, err := NewParquetWriter(&buf, parquet.MaxPageSize(10000))
 	assert.Nil(b, err, "benchmark read")
 	input := getPeople(100000, b.N)
 	for _, rowgroup := range input {
@@ -948,7 +2995,7 @@ func BenchmarkRead(b *testing.B) {
 
 func BenchmarkWrite(b *testing.B) {
 	var buf bytes.Buffer
-	w, err := NewParquetWriter(&buf, MaxPageSize(10000))
+	w, err := NewParquetWriter(&buf, parquet.MaxPageSize(10000))
 	assert.Nil(b, err, "benchmark write")
 	input := getPeople(b.N, b.N)
 	rg := input[0]
@@ -1058,3 +3105,18 @@ type Document struct {
 	Links []Link
 	Names []Name
 }
+
+func pint32(i int32) *int32       { return &i }
+func puint32(i uint32) *uint32    { return &i }
+func pint64(i int64) *int64       { return &i }
+func puint64(i uint64) *uint64    { return &i }
+func pbool(b bool) *bool          { return &b }
+func pstring(s string) *string    { return &s }
+func pfloat32(f float32) *float32 { return &f }
+func pfloat64(f float64) *float64 { return &f }
+
+func annotation(t *testing.T, s string) parquet.Annotation {
+	a, err := parquet.ParseAnnotation(s)
+	assert.NoError(t, err)
+	return a
+}
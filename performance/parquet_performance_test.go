@@ -2,10 +2,13 @@ package performance
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"math/rand"
 	"testing"
 
 	"github.com/bxcodec/faker/v3"
+	"github.com/parsyl/parquet"
 	"github.com/parsyl/parquet/performance/base"
 	"github.com/parsyl/parquet/performance/message"
 )
@@ -21,6 +24,15 @@ type parquetWriter interface {
 	Close() error
 }
 
+// addNoError gives ParquetWriter the Add method of the base writer
+type addNoError struct {
+	*ParquetWriter
+}
+
+func (w addNoError) Add(rec message.Message) {
+	w.AddNoError(rec)
+}
+
 func generateTestData(count int) []message.Message {
 	res := make([]message.Message, count)
 	for i := 0; i < count; i++ {
@@ -95,14 +107,30 @@ func BenchmarkWrite(b *testing.B) {
 			if err != nil {
 				b.Fatal(err)
 			}
-			return writer
+			return addNoError{writer}
 		}
 		benchmarkParquet(b, data, &optBuff, getWriter)
 	})
 
+	var concBuff bytes.Buffer
+	b.Run("opt concurrency", func(b *testing.B) {
+		getWriter := func(buf *bytes.Buffer) parquetWriter {
+			writer, err := NewParquetWriter(&concBuff, parquet.WriterConcurrency(4))
+			if err != nil {
+				b.Fatal(err)
+			}
+			return addNoError{writer}
+		}
+		benchmarkParquet(b, data, &concBuff, getWriter)
+	})
+
 	baseBytes := baseBuff.Bytes()
 	optBytes := optBuff.Bytes()
 
+	if !bytes.Equal(optBytes, concBuff.Bytes()) {
+		b.Fatal("concurrent writer produced a different file")
+	}
+
 	// to make sure we didn't break anything
 	if len(baseBytes) != len(optBytes) || len(baseBytes) == 0 {
 		b.Fatal("length", baseBuff.Len(), optBuff.Len())
@@ -114,3 +142,94 @@ func BenchmarkWrite(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkRead(b *testing.B) {
+	data := generateTestData(inputSize)
+
+	var buf bytes.Buffer
+	writer, err := NewParquetWriter(&buf)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := range data {
+		writer.Add(data[i])
+		if i%writeBatch == 0 {
+			if err := writer.Write(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	if err := writer.Write(); err != nil {
+		b.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	readAll := func(b *testing.B, opts ...parquet.ReaderOption) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r, err := NewParquetReader(bytes.NewReader(buf.Bytes()), opts...)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			var n int
+			for r.Next() {
+				var m message.Message
+				r.Scan(&m)
+				n++
+			}
+
+			if err := r.Error(); err != nil {
+				b.Fatal(err)
+			}
+
+			if n != inputSize {
+				b.Fatalf("expected %d rows, got %d", inputSize, n)
+			}
+		}
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		readAll(b)
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			r, err := NewParquetReader(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			var n int
+			dst := make([]message.Message, 1024)
+			for {
+				m, err := r.ReadBatch(dst)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+				n += m
+			}
+
+			if n != inputSize {
+				b.Fatalf("expected %d rows, got %d", inputSize, n)
+			}
+		}
+	})
+
+	for _, n := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency %d", n), func(b *testing.B) {
+			readAll(b, parquet.WithConcurrency(n))
+		})
+	}
+}
@@ -0,0 +1,663 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// ReaderOption is an optional argument of NewReader
+type ReaderOption func(*readerOptions)
+
+type readerOptions struct {
+	strict          bool
+	caseInsensitive bool
+	streaming       bool
+	concurrency     int
+}
+
+// StreamPages makes the reader decode one page of each column at a time
+// instead of reading each row group into memory.  The next page of a column
+// is read once the values of the current page have all been scanned.
+func StreamPages(o *readerOptions) {
+	o.streaming = true
+}
+
+// StrictSchema makes NewReader return a *SchemaError that lists every
+// difference between the file's schema and the struct.  Without it columns
+// that aren't in the struct are ignored, fields that aren't in the file
+// aren't set by Scan, and INT32 and FLOAT columns are widened when they
+// are read into int64, uint64 or float64 fields.
+func StrictSchema(o *readerOptions) {
+	o.strict = true
+}
+
+// CaseInsensitive makes the reader match the columns of the file to the
+// fields of the struct without regard to case when they don't match by
+// field_id or by name.
+func CaseInsensitive(o *readerOptions) {
+	o.caseInsensitive = true
+}
+
+// WithConcurrency makes the reader decode the column chunks of each row
+// group with n goroutines.  It also decodes the next row group in the
+// background while the current row group is being scanned.  WithConcurrency
+// has no effect when streaming pages.
+func WithConcurrency(n int) ReaderOption {
+	return func(o *readerOptions) {
+		o.concurrency = n
+	}
+}
+
+// Reader reads records of type T from a parquet file
+type Reader[T any] struct {
+	readerOptions
+
+	// columns creates the columns of a row group
+	columns func(sch.CompressionCodec) []Column[T]
+
+	fields         map[string]Column[T]
+	fieldNames     []string
+	cursor         int64
+	rows           int64
+	rowGroupCursor int64
+	rowGroupCount  int64
+	pages          map[string][]Page
+	meta           *Metadata
+	err            error
+
+	// chunks holds the position of the next page of each
+	// column in the current row group when streaming pages.
+	chunks map[string]*Page
+
+	prefetched *readerPrefetch[T]
+
+	// r is an io.SectionReader of ra, so the file is
+	// only ever read with ReadAt
+	r         io.ReadSeeker
+	ra        io.ReaderAt
+	size      int64
+	rowGroups []RowGroup
+
+	// rowGroup is the index of the next row group to be read
+	rowGroup int
+}
+
+// NewReader creates a Reader for the struct T.  The columns of T are found
+// with reflection, using the same rules as parquetgen, so no code has to be
+// generated.
+func NewReader[T any](r io.ReadSeeker, opts ...ReaderOption) (*Reader[T], error) {
+	c, err := newReflectCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+	return NewCodecReader(r, c, opts...)
+}
+
+// NewCodecReader creates a Reader that uses the columns
+// of c (see Codec) to read the records.
+func NewCodecReader[T any](r io.ReadSeeker, c Codec[T], opts ...ReaderOption) (*Reader[T], error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	ra, ok := r.(io.ReaderAt)
+	if !ok {
+		ra = NewReaderAt(r)
+	}
+
+	return newReader(ra, size, c.Fields, opts...)
+}
+
+// NewCodecReaderAt is like NewCodecReader but reads the
+// parquet file, which is size bytes long, from r.
+func NewCodecReaderAt[T any](r io.ReaderAt, size int64, c Codec[T], opts ...ReaderOption) (*Reader[T], error) {
+	return newReader(r, size, c.Fields, opts...)
+}
+
+func newReader[T any](r io.ReaderAt, size int64, columns func(sch.CompressionCodec) []Column[T], opts ...ReaderOption) (*Reader[T], error) {
+	ff := columns(sch.CompressionCodec_UNCOMPRESSED)
+	pr := &Reader[T]{
+		columns: columns,
+		r:       io.NewSectionReader(r, 0, size),
+		ra:      r,
+		size:    size,
+	}
+
+	for _, opt := range opts {
+		opt(&pr.readerOptions)
+	}
+
+	schema := make([]Field, len(ff))
+	for i, f := range ff {
+		pr.fieldNames = append(pr.fieldNames, f.Name())
+		schema[i] = f.Schema()
+	}
+
+	meta := New(schema...)
+	meta.SetCaseInsensitive(pr.caseInsensitive)
+	if err := meta.ReadFooterAt(r, size); err != nil {
+		return nil, err
+	}
+
+	var diffs []SchemaDiff
+	for _, d := range meta.SchemaDiffs() {
+		if pr.strict || !d.Safe() {
+			diffs = append(diffs, d)
+		}
+	}
+
+	if len(diffs) > 0 {
+		return nil, &SchemaError{Diffs: diffs}
+	}
+
+	pr.rows = meta.Rows()
+	var err error
+	pr.pages, err = meta.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	pr.rowGroups = meta.RowGroups()
+	pr.meta = meta
+
+	return pr, pr.readRowGroup()
+}
+
+// Levels are the definition and repetition levels of a column
+type Levels struct {
+	Name string
+	Defs []uint8
+	Reps []uint8
+}
+
+// Levels returns the levels of each column that haven't been scanned yet
+func (p *Reader[T]) Levels() []Levels {
+	var out []Levels
+	for _, name := range p.fieldNames {
+		f := p.fields[name]
+		d, r := f.Levels()
+		out = append(out, Levels{Name: f.Name(), Defs: d, Reps: r})
+	}
+	return out
+}
+
+// Error returns the error that stopped Next
+func (p *Reader[T]) Error() error {
+	return p.err
+}
+
+func (p *Reader[T]) readRowGroup() error {
+	p.rowGroupCursor = 0
+
+	if p.rowGroup >= len(p.rowGroups) {
+		p.rowGroupCount = 0
+		return nil
+	}
+
+	i := p.rowGroup
+	var rg readerRowGroup[T]
+	if p.prefetched != nil && p.prefetched.index == i {
+		rg = <-p.prefetched.ch
+	} else {
+		rg = p.decodeRowGroup(i)
+	}
+
+	p.prefetched = nil
+	if rg.err != nil {
+		return rg.err
+	}
+
+	p.fields = rg.fields
+	p.chunks = rg.chunks
+	p.rowGroupCount = p.rowGroups[i].Rows
+	p.rowGroup++
+
+	if p.concurrency > 1 && !p.streaming && p.rowGroup < len(p.rowGroups) {
+		pf := &readerPrefetch[T]{index: p.rowGroup, ch: make(chan readerRowGroup[T], 1)}
+		go func() {
+			pf.ch <- p.decodeRowGroup(pf.index)
+		}()
+		p.prefetched = pf
+	}
+	return nil
+}
+
+// readerRowGroup holds the columns of a decoded row group
+type readerRowGroup[T any] struct {
+	fields map[string]Column[T]
+	chunks map[string]*Page
+	err    error
+}
+
+// readerPrefetch is the row group that is being decoded in
+// the background while the current row group is scanned.
+type readerPrefetch[T any] struct {
+	index int
+	ch    chan readerRowGroup[T]
+}
+
+// readerColumn is a column chunk that needs to be decoded
+type readerColumn[T any] struct {
+	field Column[T]
+	page  Page
+}
+
+func (c readerColumn[T]) read(r io.ReadSeeker) error {
+	if _, err := r.Seek(c.page.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := c.field.Read(r, c.page); err != nil {
+		return fmt.Errorf("unable to read field %s, err: %s", c.field.Name(), err)
+	}
+	return nil
+}
+
+// decodeRowGroup reads the column chunks of the i'th row group.  It
+// does not change the state of p so that it can be called from another
+// goroutine.
+func (p *Reader[T]) decodeRowGroup(i int) readerRowGroup[T] {
+	out := readerRowGroup[T]{
+		fields: map[string]Column[T]{},
+		chunks: map[string]*Page{},
+	}
+
+	for _, f := range p.columns(sch.CompressionCodec_UNCOMPRESSED) {
+		out.fields[f.Name()] = f
+	}
+
+	var cols []readerColumn[T]
+	for _, col := range p.rowGroups[i].Columns() {
+		name, ok := p.meta.Column(col.MetaData.PathInSchema)
+		if !ok {
+			continue
+		}
+
+		f, ok := out.fields[name]
+		if !ok {
+			continue
+		}
+
+		pages := p.pages[name]
+		if len(pages) <= i {
+			break
+		}
+
+		pg := pages[i]
+		if p.streaming {
+			out.chunks[name] = &pg
+			continue
+		}
+
+		cols = append(cols, readerColumn[T]{field: f, page: pg})
+	}
+
+	out.err = p.readColumns(cols)
+	return out
+}
+
+// readColumns decodes the column chunks with p.concurrency goroutines.
+// Each goroutine gets its own io.SectionReader of p.ra.
+func (p *Reader[T]) readColumns(cols []readerColumn[T]) error {
+	if p.concurrency <= 1 {
+		r := io.NewSectionReader(p.ra, 0, p.size)
+		for _, c := range cols {
+			if err := c.read(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ch := make(chan readerColumn[T])
+	errs := make(chan error, len(cols))
+	var wg sync.WaitGroup
+	for i := 0; i < p.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := io.NewSectionReader(p.ra, 0, p.size)
+			for c := range ch {
+				errs <- c.read(r)
+			}
+		}()
+	}
+
+	for _, c := range cols {
+		ch <- c
+	}
+	close(ch)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RowGroups returns a summary of each row group in the file.
+func (p *Reader[T]) RowGroups() []RowGroup {
+	return p.rowGroups
+}
+
+// ReadRowGroup reads the i'th row group so that the next call to
+// Next and Scan returns the first row of that row group.
+func (p *Reader[T]) ReadRowGroup(i int) error {
+	if i < 0 || i >= len(p.rowGroups) {
+		return fmt.Errorf("row group %d out of range, file has %d row groups", i, len(p.rowGroups))
+	}
+
+	p.cursor = 0
+	for _, rg := range p.rowGroups[:i] {
+		p.cursor += rg.Rows
+	}
+
+	p.rowGroup = i
+	p.err = p.readRowGroup()
+	return p.err
+}
+
+// SeekToRow moves the reader so that the next call to Next and Scan
+// returns the n'th row (starting at 0) of the file.
+func (p *Reader[T]) SeekToRow(n int64) error {
+	if n < 0 || n > p.rows {
+		return fmt.Errorf("row %d out of range, file has %d rows", n, p.rows)
+	}
+
+	var start int64
+	for i, rg := range p.rowGroups {
+		if n < start+rg.Rows {
+			if err := p.ReadRowGroup(i); err != nil {
+				return err
+			}
+			p.err = p.skip(n - start)
+			return p.err
+		}
+		start += rg.Rows
+	}
+
+	p.cursor = p.rows
+	p.rowGroup = len(p.rowGroups)
+	return p.readRowGroup()
+}
+
+// skip discards the next n rows of the current row group.
+func (p *Reader[T]) skip(n int64) error {
+	rg := p.rowGroups[p.rowGroup-1]
+	for _, col := range rg.Columns() {
+		name, ok := p.meta.Column(col.MetaData.PathInSchema)
+		if !ok {
+			continue
+		}
+
+		f, ok := p.fields[name]
+		if !ok {
+			continue
+		}
+
+		k := n
+		pg, ok := p.chunks[name]
+		if ok {
+			oi, err := OffsetIndex(p.r, col)
+			if err != nil {
+				return fmt.Errorf("unable to read offset index of field %s, err: %s", name, err)
+			}
+			if oi != nil {
+				k = SeekPage(pg, oi, k)
+			}
+		}
+
+		for k > 0 {
+			if ok {
+				if err := f.ReadPage(p.r, pg); err != nil {
+					return fmt.Errorf("unable to read field %s, err: %s", name, err)
+				}
+			}
+
+			s := f.Skip(int(k))
+			if s == 0 {
+				return fmt.Errorf("unable to skip %d rows of field %s", k, name)
+			}
+			k -= int64(s)
+		}
+	}
+
+	p.cursor += n
+	p.rowGroupCursor += n
+	return nil
+}
+
+// readPages makes sure that each field has the values for the
+// next row when streaming pages.
+func (p *Reader[T]) readPages() error {
+	for _, name := range p.fieldNames {
+		pg, ok := p.chunks[name]
+		if !ok {
+			continue
+		}
+
+		if err := p.fields[name].ReadPage(p.r, pg); err != nil {
+			return fmt.Errorf("unable to read field %s, err: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// Rows is the number of rows in the file
+func (p *Reader[T]) Rows() int64 {
+	return p.rows
+}
+
+// Metadata returns the created_by and key_value_metadata of the file
+func (p *Reader[T]) Metadata() FileMetadata {
+	return p.meta.FileMetadata()
+}
+
+// Next moves the reader to the next row.  It is false once
+// all of the rows have been read or if there was an error.
+func (p *Reader[T]) Next() bool {
+	if p.err == nil && p.cursor >= p.rows {
+		return false
+	}
+	if p.rowGroupCursor >= p.rowGroupCount {
+		p.err = p.readRowGroup()
+		if p.err != nil {
+			return false
+		}
+	}
+
+	if p.streaming {
+		p.err = p.readPages()
+		if p.err != nil {
+			return false
+		}
+	}
+
+	p.cursor++
+	p.rowGroupCursor++
+	return true
+}
+
+// Scan sets the fields of x to the values of the current row
+func (p *Reader[T]) Scan(x *T) {
+	if p.err != nil {
+		return
+	}
+
+	for _, name := range p.fieldNames {
+		f := p.fields[name]
+		f.Scan(x)
+	}
+}
+
+// All returns an iterator over the rows that haven't been read yet.  If
+// there is an error it is yielded (with the zero value of T) and the
+// iteration stops.
+func (p *Reader[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for p.Next() {
+			var x T
+			p.Scan(&x)
+			if !yield(x, nil) {
+				return
+			}
+		}
+
+		if p.err != nil {
+			var x T
+			yield(x, p.err)
+		}
+	}
+}
+
+// ReadBatch reads the next rows into dst and returns the number of rows
+// that were read, which is less than len(dst) at the end of the file.
+// Each column sets the field of every row in the batch before the next
+// column is scanned.  ReadBatch returns 0 and io.EOF once all of the rows
+// have been read.
+func (p *Reader[T]) ReadBatch(dst []T) (int, error) {
+	var n int
+	if p.streaming {
+		// the pages of each column are read
+		// one row at a time (see readPages)
+		for n < len(dst) && p.Next() {
+			p.Scan(&dst[n])
+			n++
+		}
+	} else {
+		for n < len(dst) && p.err == nil && p.cursor < p.rows {
+			if p.rowGroupCursor >= p.rowGroupCount {
+				p.err = p.readRowGroup()
+				if p.err != nil || p.rowGroupCount == 0 {
+					break
+				}
+			}
+
+			m := min(len(dst)-n, int(p.rowGroupCount-p.rowGroupCursor))
+			for _, name := range p.fieldNames {
+				scanBatch(p.fields[name], dst[n:n+m])
+			}
+
+			n += m
+			p.cursor += int64(m)
+			p.rowGroupCursor += int64(m)
+		}
+	}
+
+	if p.err != nil {
+		return n, p.err
+	}
+
+	if n == 0 && len(dst) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// ReadColumnInt32 returns the decoded values of the INT32 column name (the
+// names of nested columns are joined with a '.') in the current row group,
+// along with its definition and repetition levels (which are nil if the
+// column is required or isn't repeated).  Only the values of the rows that
+// haven't been scanned are returned.  The slices are the column's buffers,
+// so they must not be changed and are only valid until the next row group
+// is read (by Next or ReadRowGroup).  A column can't be read when streaming
+// pages.
+func (p *Reader[T]) ReadColumnInt32(name string) (vals []int32, defs, reps []uint8, err error) {
+	return readColumn[int32](p, name)
+}
+
+// ReadColumnUint32 returns the values of an INT32 column whose
+// converted type is UINT_32 (see ReadColumnInt32)
+func (p *Reader[T]) ReadColumnUint32(name string) (vals []uint32, defs, reps []uint8, err error) {
+	return readColumn[uint32](p, name)
+}
+
+// ReadColumnInt64 returns the values of an INT64 column (see ReadColumnInt32)
+func (p *Reader[T]) ReadColumnInt64(name string) (vals []int64, defs, reps []uint8, err error) {
+	return readColumn[int64](p, name)
+}
+
+// ReadColumnUint64 returns the values of an INT64 column whose
+// converted type is UINT_64 (see ReadColumnInt32)
+func (p *Reader[T]) ReadColumnUint64(name string) (vals []uint64, defs, reps []uint8, err error) {
+	return readColumn[uint64](p, name)
+}
+
+// ReadColumnFloat32 returns the values of a FLOAT column (see ReadColumnInt32)
+func (p *Reader[T]) ReadColumnFloat32(name string) (vals []float32, defs, reps []uint8, err error) {
+	return readColumn[float32](p, name)
+}
+
+// ReadColumnFloat64 returns the values of a DOUBLE column (see ReadColumnInt32)
+func (p *Reader[T]) ReadColumnFloat64(name string) (vals []float64, defs, reps []uint8, err error) {
+	return readColumn[float64](p, name)
+}
+
+// ReadColumnBool returns the values of a BOOLEAN column (see ReadColumnInt32)
+func (p *Reader[T]) ReadColumnBool(name string) (vals []bool, defs, reps []uint8, err error) {
+	return readColumn[bool](p, name)
+}
+
+// ReadColumnString returns the values of a BYTE_ARRAY column (see ReadColumnInt32)
+func (p *Reader[T]) ReadColumnString(name string) (vals []string, defs, reps []uint8, err error) {
+	return readColumn[string](p, name)
+}
+
+// valueBuffer is a Column whose decoded values can be read
+// without scanning them into records (see ReadColumnInt32).
+type valueBuffer interface {
+	buffer() (vals any, defs, reps []uint8)
+}
+
+// readColumn returns the values and levels of a column (see ReadColumnInt32)
+func readColumn[V Value, T any](p *Reader[T], name string) ([]V, []uint8, []uint8, error) {
+	if p.err != nil {
+		return nil, nil, nil, p.err
+	}
+
+	if p.streaming {
+		return nil, nil, nil, fmt.Errorf("unable to read column %s, err: the reader streams pages", name)
+	}
+
+	f, ok := p.fields[name]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unable to read column %s, err: it is not in the schema", name)
+	}
+
+	b, ok := f.(valueBuffer)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unable to read column %s, err: its values aren't buffered", name)
+	}
+
+	v, defs, reps := b.buffer()
+	vals, ok := v.([]V)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unable to read column %s, err: it has %T values, not %T", name, v, vals)
+	}
+	return vals, defs, reps, nil
+}
+
+// batchScanner is a Column that can set the fields of
+// several records without a call to Scan for each one.
+type batchScanner[T any] interface {
+	scanBatch(dst []T)
+}
+
+func scanBatch[T any](f Column[T], dst []T) {
+	if b, ok := f.(batchScanner[T]); ok {
+		b.scanBatch(dst)
+		return
+	}
+
+	for i := range dst {
+		f.Scan(&dst[i])
+	}
+}
@@ -0,0 +1,243 @@
+package parquet
+
+import (
+	"fmt"
+	"reflect"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// reflectColumn is a Column whose values are read from and written to
+// the fields of T with reflection.  The definition and repetition levels
+// are computed with the same rules as the code that parquetgen generates.
+type reflectColumn[T any] struct {
+	*leafColumn
+
+	// index is the index (see reflect.Value.FieldByIndex)
+	// of the field at each level of the column's path.
+	index [][]int
+}
+
+// reflectLeaf is a column of a struct that was found by reflectLeaves
+type reflectLeaf struct {
+	path  []string
+	index [][]int
+	types []RepetitionType
+	kind  reflect.Kind
+	id    int32
+
+	// annotations are the annotation of each level of path
+	annotations []Annotation
+}
+
+// reflectCodec is the Codec of T that NewWriter and NewReader use.  The
+// columns are found the same way parquetgen finds them: exported fields
+// are columns (named by their parquet tag or else by the field's name),
+// fields tagged with "-" are left out, pointers are optional, slices are
+// repeated, the fields of embedded structs are columns of the struct that
+// embeds them and other structs are nested groups.
+type reflectCodec[T any] struct {
+	leaves []reflectLeaf
+}
+
+func newReflectCodec[T any]() (Codec[T], error) {
+	var t T
+	typ := reflect.TypeOf(t)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is not a struct", typ)
+	}
+
+	leaves, err := reflectLeaves(typ, nil, nil, nil, nil, nil, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("%v has no columns", typ)
+	}
+
+	return reflectCodec[T]{leaves: leaves}, nil
+}
+
+func (r reflectCodec[T]) Fields(c sch.CompressionCodec) []Column[T] {
+	out := make([]Column[T], len(r.leaves))
+	for i, l := range r.leaves {
+		out[i] = newReflectColumn[T](l, c)
+	}
+	return out
+}
+
+// reflectLeaves returns the leaf columns of the struct typ.  prefix is
+// the index of typ in the struct that embeds it.
+func reflectLeaves(typ reflect.Type, prefix []int, pth []string, index [][]int, types []RepetitionType, annotations []Annotation, seen map[reflect.Type]bool) ([]reflectLeaf, error) {
+	if seen[typ] {
+		return nil, fmt.Errorf("%v is recursive", typ)
+	}
+	seen[typ] = true
+	defer delete(seen, typ)
+
+	var out []reflectLeaf
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag, err := ParseTag(f.Tag.Get("parquet"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag on field %s, err: %s", f.Name, err)
+		}
+		name := tag.Name
+
+		if name == "-" {
+			continue
+		}
+
+		fi := append(prefix[:len(prefix):len(prefix)], i)
+		if f.Anonymous {
+			if f.Type.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("unsupported embedded type %v", f.Type)
+			}
+
+			children, err := reflectLeaves(f.Type, fi, pth, index, types, annotations, seen)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, children...)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		ft := f.Type
+		rt := Required
+		switch ft.Kind() {
+		case reflect.Ptr:
+			rt = Optional
+			ft = ft.Elem()
+		case reflect.Slice:
+			rt = Repeated
+			ft = ft.Elem()
+		}
+
+		p := append(pth[:len(pth):len(pth)], name)
+		ix := append(index[:len(index):len(index)], fi)
+		ts := append(types[:len(types):len(types)], rt)
+		as := append(annotations[:len(annotations):len(annotations)], tag.Annotation)
+
+		if _, ok := kinds[ft.Kind()]; ok {
+			out = append(out, reflectLeaf{path: p, index: ix, types: ts, kind: ft.Kind(), id: tag.ID, annotations: as})
+			continue
+		}
+
+		if ft.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("unsupported type %v of field %s", f.Type, f.Name)
+		}
+
+		children, err := reflectLeaves(ft, nil, p, ix, ts, as, seen)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, children...)
+	}
+	return out, nil
+}
+
+func newReflectColumn[T any](l reflectLeaf, c sch.CompressionCodec) *reflectColumn[T] {
+	return &reflectColumn[T]{
+		leafColumn: newLeafColumn(l.path, l.types, l.kind, l.id, l.annotations, c),
+		index:      l.index,
+	}
+}
+
+// walk calls f with each value (and its levels) of the column in v.  The
+// value is invalid if it is null.
+func (c *reflectColumn[T]) walk(v reflect.Value, depth int, def, rep uint8, f func(v reflect.Value, def, rep uint8)) {
+	if depth == len(c.index) {
+		f(v, def, rep)
+		return
+	}
+
+	fv := v.FieldByIndex(c.index[depth])
+	switch c.types[depth] {
+	case Optional:
+		if fv.IsNil() {
+			f(reflect.Value{}, def, rep)
+			return
+		}
+		c.walk(fv.Elem(), depth+1, def+1, rep, f)
+	case Repeated:
+		if fv.Len() == 0 {
+			f(reflect.Value{}, def, rep)
+			return
+		}
+		for i := 0; i < fv.Len(); i++ {
+			r := rep
+			if i > 0 {
+				r = c.reps[depth]
+			}
+			c.walk(fv.Index(i), depth+1, def+1, r, f)
+		}
+	default:
+		c.walk(fv, depth+1, def, rep, f)
+	}
+}
+
+func (c *reflectColumn[T]) Add(rec T) {
+	c.walk(reflect.ValueOf(rec), 0, 0, 0, c.add)
+}
+
+func (c *reflectColumn[T]) Validate(rec T, checks Checks) error {
+	var err error
+	c.walk(reflect.ValueOf(rec), 0, 0, 0, func(v reflect.Value, _, _ uint8) {
+		if err == nil && v.IsValid() {
+			err = c.vals.check(checks, c.Name(), v)
+		}
+	})
+	return err
+}
+
+func (c *reflectColumn[T]) Scan(rec *T) {
+	v := reflect.ValueOf(rec).Elem()
+	c.scan(func(def uint8, indices []int, i int) {
+		c.set(v, def, indices, i)
+	})
+}
+
+// set creates the pointers and slice elements of v that are defined by
+// def and sets the leaf to the i'th value if it isn't null (-1).
+func (c *reflectColumn[T]) set(v reflect.Value, def uint8, indices []int, i int) {
+	var d uint8
+	var r int
+	for depth, t := range c.types {
+		fv := v.FieldByIndex(c.index[depth])
+		switch t {
+		case Optional:
+			if def <= d {
+				return
+			}
+			d++
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			v = fv.Elem()
+		case Repeated:
+			if def <= d {
+				return
+			}
+			d++
+			j := indices[r]
+			r++
+			for fv.Len() <= j {
+				fv.Set(reflect.Append(fv, reflect.Zero(fv.Type().Elem())))
+			}
+			v = fv.Index(j)
+		default:
+			v = fv
+		}
+	}
+
+	c.vals.set(v, i)
+}
@@ -0,0 +1,135 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/valyala/bytebufferpool"
+)
+
+// Spill holds the encoded and compressed pages of a row group
+// until the row group is written.  A writer moves each page into
+// a Spill as soon as the page is full, so only the current page
+// of each column has to be kept in memory as Go values.  The pages
+// are kept in memory (see NewSpill) or in a temporary file (see
+// NewFileSpill).
+type Spill struct {
+	buf   *bytebufferpool.ByteBuffer
+	file  *os.File
+	size  int64
+	pages [][]spillPage
+}
+
+type spillPage struct {
+	offset int64
+	size   int64
+}
+
+// NewSpill creates a Spill for the given number of
+// columns that keeps the pages in memory.
+func NewSpill(columns int) *Spill {
+	return &Spill{
+		buf:   &bytebufferpool.ByteBuffer{},
+		pages: make([][]spillPage, columns),
+	}
+}
+
+// NewFileSpill creates a Spill for the given number of columns that
+// writes the pages to a temporary file in dir.  If dir is the empty
+// string the default directory for temporary files is used.  The file
+// is removed by Close.
+func NewFileSpill(columns int, dir string) (*Spill, error) {
+	f, err := os.CreateTemp(dir, "parquet-spill-*")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create spill file, err: %s", err)
+	}
+
+	return &Spill{
+		file:  f,
+		pages: make([][]spillPage, columns),
+	}, nil
+}
+
+// Add appends the page (including its header) of column col
+func (s *Spill) Add(col int, page []byte) error {
+	var err error
+	if s.file != nil {
+		_, err = s.file.WriteAt(page, s.size)
+	} else {
+		_, err = s.buf.Write(page)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to spill page, err: %s", err)
+	}
+
+	s.pages[col] = append(s.pages[col], spillPage{offset: s.size, size: int64(len(page))})
+	s.size += int64(len(page))
+	return nil
+}
+
+// Size is the number of bytes of all the pages in the Spill
+func (s *Spill) Size() int64 {
+	return s.size
+}
+
+// Buffered is the number of bytes of the pages
+// that are kept in memory.
+func (s *Spill) Buffered() int64 {
+	if s.file != nil {
+		return 0
+	}
+	return s.size
+}
+
+// WriteTo writes the pages to w, ordered by column,
+// so that each column chunk is contiguous.
+func (s *Spill) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for _, pages := range s.pages {
+		for _, pg := range pages {
+			var err error
+			var m int64
+			if s.file != nil {
+				m, err = io.Copy(w, io.NewSectionReader(s.file, pg.offset, pg.size))
+			} else {
+				var i int
+				i, err = w.Write(s.buf.B[pg.offset : pg.offset+pg.size])
+				m = int64(i)
+			}
+
+			n += m
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Reset removes all of the pages
+func (s *Spill) Reset() error {
+	for i := range s.pages {
+		s.pages[i] = s.pages[i][:0]
+	}
+	s.size = 0
+
+	if s.file != nil {
+		return s.file.Truncate(0)
+	}
+	s.buf.Reset()
+	return nil
+}
+
+// Close removes the temporary file if there is one
+func (s *Spill) Close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(s.file.Name())
+}
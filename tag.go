@@ -0,0 +1,73 @@
+package parquet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tag holds the options of the parquet key of a struct tag, like
+// `parquet:"name,id=12,logical=DECIMAL(9,2)"`.  Both parquetgen and
+// the reflection of NewWriter and NewReader use ParseTag, so a struct
+// tag means the same thing to both.
+type Tag struct {
+	// Name is the name of the column ("" if the tag doesn't have
+	// one and "-" if the field isn't a column).
+	Name string
+
+	// ID is the field_id of the column (0 if there isn't one)
+	ID int32
+
+	// Logical is the logical type of the column or group in the syntax
+	// of a parquet schema ("" if there isn't one) and Annotation is
+	// what it was parsed into (see ParseAnnotation).
+	Logical    string
+	Annotation Annotation
+}
+
+// ParseTag parses the value of the parquet key of a struct tag.
+func ParseTag(tag string) (Tag, error) {
+	parts := splitTag(tag)
+	t := Tag{Name: parts[0]}
+	for _, opt := range parts[1:] {
+		switch {
+		case strings.HasPrefix(opt, "id="):
+			n, err := strconv.ParseInt(strings.TrimPrefix(opt, "id="), 10, 32)
+			if err != nil || n <= 0 {
+				return Tag{}, fmt.Errorf("id has to be a positive int32: %s", opt)
+			}
+			t.ID = int32(n)
+		case strings.HasPrefix(opt, "logical="):
+			t.Logical = strings.TrimPrefix(opt, "logical=")
+			a, err := ParseAnnotation(t.Logical)
+			if err != nil {
+				return Tag{}, fmt.Errorf("invalid logical type %s: %s", t.Logical, err)
+			}
+			t.Annotation = a
+		default:
+			return Tag{}, fmt.Errorf("unknown option %s", opt)
+		}
+	}
+	return t, nil
+}
+
+// splitTag splits the options of a tag on the commas that
+// aren't in the arguments of a logical type like DECIMAL(9,2).
+func splitTag(t string) []string {
+	var out []string
+	var depth, start int
+	for i, c := range t {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, t[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, t[start:])
+}
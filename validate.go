@@ -0,0 +1,69 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+var (
+	// ErrStringTooLong means a string is longer than Checks.MaxStringBytes
+	ErrStringTooLong = errors.New("string is too long")
+	// ErrInvalidUTF8 means a string isn't valid UTF-8
+	ErrInvalidUTF8 = errors.New("string is not valid UTF-8")
+	// ErrNaN means a float is NaN
+	ErrNaN = errors.New("float is NaN")
+)
+
+// RecordError is returned when a record is rejected before any of
+// it is written, so the writer can still be used (for example, after
+// the record has been sent to a dead letter queue).  Field is the
+// column that failed a check or empty if the record was rejected by
+// a validator.
+type RecordError struct {
+	Field string
+	Err   error
+}
+
+func (e *RecordError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid record, err: %s", e.Err)
+	}
+	return fmt.Sprintf("invalid value for %s, err: %s", e.Field, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Checks are the built in validations of the values of a record.
+// The zero value doesn't check anything.
+type Checks struct {
+	// MaxStringBytes is the maximum length of a string (0 means no limit)
+	MaxStringBytes int
+	// UTF8 rejects strings that aren't valid UTF-8
+	UTF8 bool
+	// NaN rejects floats that are NaN
+	NaN bool
+}
+
+// String checks the string s of column col
+func (c Checks) String(col, s string) error {
+	if c.MaxStringBytes > 0 && len(s) > c.MaxStringBytes {
+		return &RecordError{Field: col, Err: fmt.Errorf("%w: %d bytes (max %d)", ErrStringTooLong, len(s), c.MaxStringBytes)}
+	}
+
+	if c.UTF8 && !utf8.ValidString(s) {
+		return &RecordError{Field: col, Err: ErrInvalidUTF8}
+	}
+	return nil
+}
+
+// Float checks the float f of column col
+func (c Checks) Float(col string, f float64) error {
+	if c.NaN && math.IsNaN(f) {
+		return &RecordError{Field: col, Err: ErrNaN}
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+
+	sch "github.com/parsyl/parquet/schema"
+	"github.com/valyala/bytebufferpool"
+)
+
+// values holds the (non-null) values of a column whose type is only
+// known at runtime.  The values are encoded and decoded the same way
+// as the values of the generated fields, so the pages (and their
+// statistics) are the same as the ones written by generated code.
+type values interface {
+	// add appends v, which has to be of the column's kind
+	add(v reflect.Value)
+	// set sets v to the i'th value
+	set(v reflect.Value, i int)
+	// get returns the i'th value
+	get(i int) any
+	// check runs the checks on v (see Checks)
+	check(c Checks, col string, v reflect.Value) error
+	len() int
+	// size is the number of bytes that the values take up once encoded
+	size() int
+	// discard removes the first n values
+	discard(n int)
+	// all returns the values as a slice of the column's Go type
+	all() any
+	// encode writes the values with the PLAIN encoding
+	encode(buf *bytebufferpool.ByteBuffer) error
+	// decode reads n values of physical type t.  sizes is the
+	// number of values in each page (only bools need it).
+	decode(r io.Reader, t sch.Type, n int, sizes []int) error
+	// stats are the statistics of the values.  defs are the definition
+	// levels of an optional column (nil if the column is required).
+	stats(defs []uint8, maxDef uint8) Stats
+}
+
+// kinds are the reflect.Kinds that can be written to a column
+var kinds = map[reflect.Kind]struct {
+	typ       FieldFunc
+	goType    reflect.Type
+	newValues func() values
+}{
+	reflect.Int32:   {typ: Int32Type, goType: reflect.TypeOf(int32(0)), newValues: func() values { return valuesOf[int32](nil) }},
+	reflect.Uint32:  {typ: Uint32Type, goType: reflect.TypeOf(uint32(0)), newValues: func() values { return valuesOf[uint32](nil) }},
+	reflect.Int64:   {typ: Int64Type, goType: reflect.TypeOf(int64(0)), newValues: func() values { return valuesOf[int64](nil) }},
+	reflect.Uint64:  {typ: Uint64Type, goType: reflect.TypeOf(uint64(0)), newValues: func() values { return valuesOf[uint64](nil) }},
+	reflect.Float32: {typ: Float32Type, goType: reflect.TypeOf(float32(0)), newValues: func() values { return valuesOf[float32](nil) }},
+	reflect.Float64: {typ: Float64Type, goType: reflect.TypeOf(float64(0)), newValues: func() values { return valuesOf[float64](nil) }},
+	reflect.Bool:    {typ: BoolType, goType: reflect.TypeOf(false), newValues: func() values { return valuesOf[bool](nil) }},
+	reflect.String:  {typ: StringType, goType: reflect.TypeOf(""), newValues: func() values { return valuesOf[string](nil) }},
+}
+
+// valuesOf returns the values of a column whose type is known at
+// compile time (see RequiredColumn and OptionalColumn).  The values
+// share vals.
+func valuesOf[V Value](vals []V) values {
+	switch v := any(vals).(type) {
+	case []int32:
+		return &numbers[int32]{vals: v, max: math.MaxInt32}
+	case []uint32:
+		return &numbers[uint32]{vals: v, max: math.MaxUint32}
+	case []int64:
+		return &numbers[int64]{vals: v, max: math.MaxInt64, narrow: widen[int32, int64]}
+	case []uint64:
+		return &numbers[uint64]{vals: v, max: math.MaxUint64, narrow: widen[uint32, uint64]}
+	case []float32:
+		return &numbers[float32]{vals: v, max: math.MaxFloat32}
+	case []float64:
+		return &numbers[float64]{vals: v, max: math.MaxFloat64, narrow: widen[float32, float64]}
+	case []bool:
+		return &bools{vals: v}
+	default:
+		return &strs{vals: any(vals).([]string)}
+	}
+}
+
+// decodeValues reads n values of physical type t (see values.decode)
+func decodeValues[V Value](r io.Reader, t sch.Type, n int, sizes []int) ([]V, error) {
+	vals := valuesOf[V](nil)
+	err := vals.decode(r, t, n, sizes)
+	return vals.all().([]V), err
+}
+
+// Int32Type sets the type of a column to INT32
+func Int32Type(se *sch.SchemaElement) {
+	t := sch.Type_INT32
+	se.Type = &t
+}
+
+// Uint32Type sets the type of a column to INT32 with the UINT_32 converted type
+func Uint32Type(se *sch.SchemaElement) {
+	t := sch.Type_INT32
+	se.Type = &t
+	ct := sch.ConvertedType_UINT_32
+	se.ConvertedType = &ct
+}
+
+// Int64Type sets the type of a column to INT64
+func Int64Type(se *sch.SchemaElement) {
+	t := sch.Type_INT64
+	se.Type = &t
+}
+
+// Uint64Type sets the type of a column to INT64 with the UINT_64 converted type
+func Uint64Type(se *sch.SchemaElement) {
+	t := sch.Type_INT64
+	se.Type = &t
+	ct := sch.ConvertedType_UINT_64
+	se.ConvertedType = &ct
+}
+
+// Float32Type sets the type of a column to FLOAT
+func Float32Type(se *sch.SchemaElement) {
+	t := sch.Type_FLOAT
+	se.Type = &t
+}
+
+// Float64Type sets the type of a column to DOUBLE
+func Float64Type(se *sch.SchemaElement) {
+	t := sch.Type_DOUBLE
+	se.Type = &t
+}
+
+// BoolType sets the type of a column to BOOLEAN
+func BoolType(se *sch.SchemaElement) {
+	t := sch.Type_BOOLEAN
+	se.Type = &t
+}
+
+// StringType sets the type of a column to BYTE_ARRAY
+func StringType(se *sch.SchemaElement) {
+	t := sch.Type_BYTE_ARRAY
+	se.Type = &t
+}
+
+type number interface {
+	int32 | uint32 | int64 | uint64 | float32 | float64
+}
+
+type numbers[N number] struct {
+	vals []N
+	// max is the largest value of N, which is where the
+	// min statistic starts (like the generated fields).
+	max N
+	// narrow reads the values of a narrower column (INT32 or FLOAT)
+	narrow func(r io.Reader, out []N) error
+}
+
+func widen[F, N number](r io.Reader, out []N) error {
+	w := make([]F, len(out))
+	if err := binary.Read(r, binary.LittleEndian, &w); err != nil {
+		return err
+	}
+	for i, x := range w {
+		out[i] = N(x)
+	}
+	return nil
+}
+
+func (n *numbers[N]) add(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Int32, reflect.Int64:
+		n.vals = append(n.vals, N(v.Int()))
+	case reflect.Uint32, reflect.Uint64:
+		n.vals = append(n.vals, N(v.Uint()))
+	default:
+		n.vals = append(n.vals, N(v.Float()))
+	}
+}
+
+func (n *numbers[N]) set(v reflect.Value, i int) {
+	switch v.Kind() {
+	case reflect.Int32, reflect.Int64:
+		v.SetInt(int64(n.vals[i]))
+	case reflect.Uint32, reflect.Uint64:
+		v.SetUint(uint64(n.vals[i]))
+	default:
+		v.SetFloat(float64(n.vals[i]))
+	}
+}
+
+func (n *numbers[N]) get(i int) any {
+	return n.vals[i]
+}
+
+func (n *numbers[N]) check(c Checks, col string, v reflect.Value) error {
+	if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+		return c.Float(col, v.Float())
+	}
+	return nil
+}
+
+func (n *numbers[N]) len() int {
+	return len(n.vals)
+}
+
+func (n *numbers[N]) size() int {
+	return len(n.vals) * binary.Size(n.max)
+}
+
+func (n *numbers[N]) discard(i int) {
+	n.vals = n.vals[i:]
+}
+
+func (n *numbers[N]) all() any {
+	return n.vals
+}
+
+func (n *numbers[N]) encode(buf *bytebufferpool.ByteBuffer) error {
+	return binary.Write(buf, binary.LittleEndian, n.vals)
+}
+
+func (n *numbers[N]) decode(r io.Reader, t sch.Type, count int, _ []int) error {
+	v := make([]N, count)
+	var err error
+	if n.narrow != nil && (t == sch.Type_INT32 || t == sch.Type_FLOAT) {
+		err = n.narrow(r, v)
+	} else {
+		err = binary.Read(r, binary.LittleEndian, &v)
+	}
+	n.vals = append(n.vals, v...)
+	return err
+}
+
+func (n *numbers[N]) stats(defs []uint8, maxDef uint8) Stats {
+	min, max := n.max, N(0)
+	for _, v := range n.vals {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	if defs == nil {
+		return &valueStats{min: numberBytes(min), max: numberBytes(max)}
+	}
+
+	s := optionalStats(defs, maxDef)
+	if len(n.vals) > 0 {
+		s.min, s.max = numberBytes(min), numberBytes(max)
+	}
+	return s
+}
+
+func numberBytes[N number](v N) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, v)
+	return buf.Bytes()
+}
+
+type bools struct {
+	vals []bool
+}
+
+func (b *bools) add(v reflect.Value) {
+	b.vals = append(b.vals, v.Bool())
+}
+
+func (b *bools) set(v reflect.Value, i int) {
+	v.SetBool(b.vals[i])
+}
+
+func (b *bools) get(i int) any {
+	return b.vals[i]
+}
+
+func (b *bools) check(Checks, string, reflect.Value) error {
+	return nil
+}
+
+func (b *bools) len() int {
+	return len(b.vals)
+}
+
+func (b *bools) size() int {
+	return (len(b.vals) + 7) / 8
+}
+
+func (b *bools) discard(n int) {
+	b.vals = b.vals[n:]
+}
+
+func (b *bools) all() any {
+	return b.vals
+}
+
+func (b *bools) encode(buf *bytebufferpool.ByteBuffer) error {
+	raw := make([]byte, (len(b.vals)+7)/8)
+	for i, v := range b.vals {
+		if v {
+			raw[i/8] = raw[i/8] | (1 << uint32(i%8))
+		}
+	}
+	_, err := buf.Write(raw)
+	return err
+}
+
+func (b *bools) decode(r io.Reader, _ sch.Type, n int, sizes []int) error {
+	v, err := GetBools(r, n, sizes)
+	b.vals = append(b.vals, v...)
+	return err
+}
+
+func (b *bools) stats(defs []uint8, maxDef uint8) Stats {
+	if defs == nil {
+		return &valueStats{}
+	}
+	return optionalStats(defs, maxDef)
+}
+
+type strs struct {
+	vals []string
+	n    int
+}
+
+func (s *strs) add(v reflect.Value) {
+	s.vals = append(s.vals, v.String())
+	s.n += 4 + v.Len()
+}
+
+func (s *strs) set(v reflect.Value, i int) {
+	v.SetString(s.vals[i])
+}
+
+func (s *strs) get(i int) any {
+	return s.vals[i]
+}
+
+func (s *strs) check(c Checks, col string, v reflect.Value) error {
+	return c.String(col, v.String())
+}
+
+func (s *strs) len() int {
+	return len(s.vals)
+}
+
+func (s *strs) size() int {
+	return s.n
+}
+
+func (s *strs) discard(n int) {
+	s.vals = s.vals[n:]
+}
+
+func (s *strs) all() any {
+	return s.vals
+}
+
+func (s *strs) encode(buf *bytebufferpool.ByteBuffer) error {
+	bs := make([]byte, 4)
+	for _, v := range s.vals {
+		binary.LittleEndian.PutUint32(bs, uint32(len(v)))
+		if _, err := buf.Write(bs); err != nil {
+			return err
+		}
+		buf.WriteString(v)
+	}
+	return nil
+}
+
+func (s *strs) decode(r io.Reader, _ sch.Type, n int, _ []int) error {
+	for j := 0; j < n; j++ {
+		var x int32
+		if err := binary.Read(r, binary.LittleEndian, &x); err != nil {
+			return err
+		}
+
+		b := make([]byte, x)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return fmt.Errorf("unable to read string, err: %s", err)
+		}
+		s.vals = append(s.vals, string(b))
+	}
+	return nil
+}
+
+func (s *strs) stats(defs []uint8, maxDef uint8) Stats {
+	var out *valueStats
+	if defs == nil {
+		out = &valueStats{}
+	} else {
+		out = optionalStats(defs, maxDef)
+	}
+
+	for i, v := range s.vals {
+		if i == 0 || v < string(out.min) {
+			out.min = []byte(v)
+		}
+		if i == 0 || v > string(out.max) {
+			out.max = []byte(v)
+		}
+	}
+	return out
+}
+
+// valueStats are the Stats of a page of values
+type valueStats struct {
+	nulls    *int64
+	min, max []byte
+}
+
+func optionalStats(defs []uint8, maxDef uint8) *valueStats {
+	var nulls int64
+	for _, def := range defs {
+		if def < maxDef {
+			nulls++
+		}
+	}
+	return &valueStats{nulls: &nulls}
+}
+
+func (s *valueStats) NullCount() *int64 {
+	return s.nulls
+}
+
+func (s *valueStats) DistinctCount() *int64 {
+	return nil
+}
+
+func (s *valueStats) Min() []byte {
+	return s.min
+}
+
+func (s *valueStats) Max() []byte {
+	return s.max
+}
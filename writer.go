@@ -0,0 +1,547 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	sch "github.com/parsyl/parquet/schema"
+	"github.com/valyala/bytebufferpool"
+)
+
+// Column is a leaf column of the records of type T that are written by
+// a Writer and read by a Reader.  It holds the current page of the column.
+type Column[T any] interface {
+	// Add adds the values of the column in r to the page
+	Add(r T)
+	// Write encodes and compresses the page and writes it to w
+	Write(w io.Writer, meta *Metadata) error
+	Schema() Field
+	// Scan sets the column's field of r to the next value
+	Scan(r *T)
+	// Read reads all of the pages of a column chunk
+	Read(r io.ReadSeeker, pg Page) error
+	// ReadPage reads the next page of a column chunk if all of
+	// the values of the pages that have been read were scanned
+	ReadPage(r io.ReadSeeker, pg *Page) error
+	// Skip discards up to n records and returns how many were discarded
+	Skip(n int) int
+	// Size is the number of bytes that the page takes up once encoded
+	Size() int
+	// Validate runs the checks on the values of the column in r
+	Validate(r T, c Checks) error
+	Name() string
+	Levels() ([]uint8, []uint8)
+}
+
+// WriterOption is an optional argument of NewWriter and OpenForAppend
+type WriterOption func(*writerOptions) error
+
+type writerOptions struct {
+	max           int
+	pageBytes     int
+	rowGroupBytes int
+	spillFile     bool
+	spillDir      string
+	maxBuffered   int
+	createdBy     string
+	keyValue      map[string]string
+	checks        Checks
+	validators    []any
+	compression   sch.CompressionCodec
+	concurrency   int
+}
+
+// Writer writes records of type T to a parquet file
+type Writer[T any] struct {
+	writerOptions
+
+	// columns creates the columns that hold the current page
+	columns func(sch.CompressionCodec) []Column[T]
+
+	// fields holds the current page of each column
+	fields []Column[T]
+
+	// len is the number of rows in the row group and
+	// pageLen is the number of rows in the current page
+	len     int
+	pageLen int
+
+	// spill holds the pages of the row group that are full
+	spill *Spill
+
+	// size is the encoded size of the largest column in the current
+	// page and bytes is the encoded size of all of its columns
+	size  int
+	bytes int
+
+	// ratio is the compression ratio of the pages that
+	// have already been written
+	ratio float64
+
+	// err is set when a page or row group that was written by Add fails
+	err error
+
+	// funcs are the validators (see WithValidator) of T
+	funcs []func(T) error
+
+	meta *Metadata
+	w    io.Writer
+}
+
+// NewWriter creates a Writer for the struct T.  The columns of T are found
+// with reflection, using the same rules as parquetgen, so no code has to be
+// generated.
+func NewWriter[T any](w io.Writer, opts ...WriterOption) (*Writer[T], error) {
+	c, err := newReflectCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+	return NewCodecWriter(w, c, opts...)
+}
+
+// NewCodecWriter creates a Writer that uses the columns
+// of c (see Codec) to write the records.
+func NewCodecWriter[T any](w io.Writer, c Codec[T], opts ...WriterOption) (*Writer[T], error) {
+	p, err := newWriter(w, c.Fields, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(magic); err != nil {
+		p.spill.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// OpenForAppend creates a Writer for the struct T that adds row groups to
+// the parquet file in rws, which must have the schema of T.  The old footer
+// is replaced by the one written by Close, which covers both the old and the
+// new row groups.  If rws has a Truncate method (like *os.File) the old
+// footer is removed right away, otherwise it is overwritten by the new row
+// groups and the new footer, which is padded so that the file doesn't end
+// before the old one did.
+func OpenForAppend[T any](rws io.ReadWriteSeeker, opts ...WriterOption) (*Writer[T], error) {
+	c, err := newReflectCodec[T]()
+	if err != nil {
+		return nil, err
+	}
+	return OpenCodecForAppend(rws, c, opts...)
+}
+
+// OpenCodecForAppend is like OpenForAppend but uses
+// the columns of c (see Codec) to write the records.
+func OpenCodecForAppend[T any](rws io.ReadWriteSeeker, c Codec[T], opts ...WriterOption) (*Writer[T], error) {
+	p, err := newWriter(rws, c.Fields, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := p.meta.Append(rws)
+	if err == nil {
+		err = truncate(rws, p.meta, start)
+	}
+
+	if err != nil {
+		p.spill.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// truncate removes everything after start if it can and then seeks to
+// start.  Otherwise the footer of m is padded to end where the file
+// ends now, since the bytes after a shorter footer would be left in place.
+func truncate(rws io.ReadWriteSeeker, m *Metadata, start int64) error {
+	if t, ok := rws.(interface{ Truncate(int64) error }); ok {
+		if err := t.Truncate(start); err != nil {
+			return fmt.Errorf("unable to truncate footer, err: %s", err)
+		}
+	} else {
+		end, err := rws.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		m.end = end
+	}
+
+	_, err := rws.Seek(start, io.SeekStart)
+	return err
+}
+
+func newWriter[T any](w io.Writer, columns func(sch.CompressionCodec) []Column[T], opts ...WriterOption) (*Writer[T], error) {
+	p := &Writer[T]{
+		writerOptions: writerOptions{
+			compression: sch.CompressionCodec_SNAPPY,
+			createdBy:   CreatedBy,
+		},
+		columns: columns,
+		w:       w,
+		ratio:   1,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&p.writerOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, v := range p.writerOptions.validators {
+		f, ok := v.(func(T) error)
+		if !ok {
+			var t T
+			return nil, fmt.Errorf("validator %T can't validate %T", v, t)
+		}
+		p.funcs = append(p.funcs, f)
+	}
+
+	if p.max == 0 && p.pageBytes == 0 {
+		p.max = 1000
+	}
+
+	p.fields = columns(p.compression)
+	if p.spillFile {
+		s, err := NewFileSpill(len(p.fields), p.spillDir)
+		if err != nil {
+			return nil, err
+		}
+		p.spill = s
+	} else {
+		p.spill = NewSpill(len(p.fields))
+	}
+
+	schema := make([]Field, len(p.fields))
+	for i, f := range p.fields {
+		schema[i] = f.Schema()
+	}
+	p.meta = New(schema...)
+	p.meta.SetCreatedBy(p.createdBy)
+	p.meta.SetKeyValueMetadata(p.keyValue)
+
+	return p, nil
+}
+
+// MaxPageSize is the maximum number of rows in each row groups' page.
+func MaxPageSize(m int) WriterOption {
+	return func(o *writerOptions) error {
+		o.max = m
+		return nil
+	}
+}
+
+// TargetPageBytes cuts a new page once the encoded size of any
+// column's page reaches n bytes.  When it is used the number of
+// rows in a page is only limited if MaxPageSize is also set.
+func TargetPageBytes(n int) WriterOption {
+	return func(o *writerOptions) error {
+		o.pageBytes = n
+		return nil
+	}
+}
+
+// TargetRowGroupBytes makes Add write the row group once its estimated
+// compressed size reaches n bytes.  The estimate is the size of the pages
+// that are already encoded plus the encoded size of the current page
+// multiplied by the compression ratio of the pages written so far.
+func TargetRowGroupBytes(n int) WriterOption {
+	return func(o *writerOptions) error {
+		o.rowGroupBytes = n
+		return nil
+	}
+}
+
+// SpillToTempFile keeps the encoded pages of the row group that is being
+// written in a temporary file in dir (or the default directory for temporary
+// files if dir is empty) instead of in memory.  The file is removed by Close.
+func SpillToTempFile(dir string) WriterOption {
+	return func(o *writerOptions) error {
+		o.spillFile = true
+		o.spillDir = dir
+		return nil
+	}
+}
+
+// MaxBufferedBytes makes Add write the row group once the encoded pages
+// that are kept in memory plus the encoded size of the current page reach
+// n bytes.
+func MaxBufferedBytes(n int) WriterOption {
+	return func(o *writerOptions) error {
+		o.maxBuffered = n
+		return nil
+	}
+}
+
+// WithKeyValueMetadata sets the key_value_metadata of the file's footer.
+// If it is passed more than once the keys of each map are added.
+func WithKeyValueMetadata(kv map[string]string) WriterOption {
+	return func(o *writerOptions) error {
+		if o.keyValue == nil {
+			o.keyValue = make(map[string]string, len(kv))
+		}
+		for k, v := range kv {
+			o.keyValue[k] = v
+		}
+		return nil
+	}
+}
+
+// WithCreatedBy sets the created_by of the file's footer, which defaults
+// to CreatedBy.  An empty string leaves created_by out.
+func WithCreatedBy(s string) WriterOption {
+	return func(o *writerOptions) error {
+		o.createdBy = s
+		return nil
+	}
+}
+
+// WithValidator adds a function that is called with each record before it
+// is added.  If it returns an error the record is rejected and Add returns
+// a *RecordError that wraps the error.
+func WithValidator[T any](v func(T) error) WriterOption {
+	return func(o *writerOptions) error {
+		o.validators = append(o.validators, v)
+		return nil
+	}
+}
+
+// MaxStringBytes rejects records with a string longer than n bytes
+func MaxStringBytes(n int) WriterOption {
+	return func(o *writerOptions) error {
+		o.checks.MaxStringBytes = n
+		return nil
+	}
+}
+
+// RejectInvalidUTF8 rejects records with a string that isn't valid UTF-8
+func RejectInvalidUTF8(o *writerOptions) error {
+	o.checks.UTF8 = true
+	return nil
+}
+
+// RejectNaN rejects records with a float that is NaN
+func RejectNaN(o *writerOptions) error {
+	o.checks.NaN = true
+	return nil
+}
+
+// WriterConcurrency sets the number of goroutines that encode and compress
+// the pages of each column.
+func WriterConcurrency(n int) WriterOption {
+	return func(o *writerOptions) error {
+		o.concurrency = n
+		return nil
+	}
+}
+
+// Uncompressed turns off the compression of the pages
+func Uncompressed(o *writerOptions) error {
+	o.compression = sch.CompressionCodec_UNCOMPRESSED
+	return nil
+}
+
+// Snappy compresses the pages with snappy (the default)
+func Snappy(o *writerOptions) error {
+	o.compression = sch.CompressionCodec_SNAPPY
+	return nil
+}
+
+// Gzip compresses the pages with gzip
+func Gzip(o *writerOptions) error {
+	o.compression = sch.CompressionCodec_GZIP
+	return nil
+}
+
+// Write writes the current row group.  Each call to Write
+// that follows a call to Add creates a new row group.
+func (p *Writer[T]) Write() error {
+	if p.err != nil {
+		return p.err
+	}
+
+	if p.len == 0 {
+		return nil
+	}
+
+	if p.pageLen > 0 {
+		if err := p.flushPage(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.spill.WriteTo(p.w); err != nil {
+		return err
+	}
+
+	if err := p.spill.Reset(); err != nil {
+		return err
+	}
+
+	p.len = 0
+	p.ratio = p.meta.CompressionRatio()
+
+	schema := make([]Field, len(p.fields))
+	for i, f := range p.fields {
+		schema[i] = f.Schema()
+	}
+	p.meta.StartRowGroup(schema...)
+	return nil
+}
+
+// flushPage encodes and compresses the current page of
+// each column and moves it to the spill.
+func (p *Writer[T]) flushPage() error {
+	bufs := make([]*bytebufferpool.ByteBuffer, len(p.fields))
+	for i := range bufs {
+		bufs[i] = buffpool.Get()
+	}
+
+	defer func() {
+		for _, buf := range bufs {
+			buffpool.Put(buf)
+		}
+	}()
+
+	if p.concurrency > 1 {
+		if err := p.encodeConcurrently(bufs); err != nil {
+			return err
+		}
+	} else {
+		for i, f := range p.fields {
+			if err := f.Write(bufs[i], p.meta); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, buf := range bufs {
+		if err := p.spill.Add(i, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	p.fields = p.columns(p.compression)
+	p.pageLen = 0
+	p.size = 0
+	p.bytes = 0
+	return nil
+}
+
+// encodeConcurrently encodes the current page of each column
+// into bufs with p.concurrency goroutines.
+func (p *Writer[T]) encodeConcurrently(bufs []*bytebufferpool.ByteBuffer) error {
+	errs := make([]error, len(p.fields))
+	sem := make(chan struct{}, p.concurrency)
+
+	var wg sync.WaitGroup
+	for i := range p.fields {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = p.fields[i].Write(bufs[i], p.meta)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes the footer.  It must be called once all
+// of the row groups have been written.
+func (p *Writer[T]) Close() error {
+	defer p.spill.Close()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	if err := p.meta.Footer(p.w); err != nil {
+		return err
+	}
+
+	_, err := p.w.Write(magic)
+	return err
+}
+
+// Add adds rec to the current page.  If rec is rejected by a check or a
+// validator the error is a *RecordError and nothing is added, so the
+// writer can still be used.  Any other error means a page or row group
+// couldn't be written and it is also returned by every call that follows.
+func (p *Writer[T]) Add(rec T) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	if err := p.validate(rec); err != nil {
+		return err
+	}
+
+	p.meta.NextDoc()
+	for _, f := range p.fields {
+		f.Add(rec)
+	}
+
+	p.len++
+	p.pageLen++
+
+	if p.pageBytes > 0 || p.rowGroupBytes > 0 || p.maxBuffered > 0 {
+		p.size, p.bytes = 0, 0
+		for _, f := range p.fields {
+			n := f.Size()
+			p.bytes += n
+			if n > p.size {
+				p.size = n
+			}
+		}
+	}
+
+	if (p.max > 0 && p.pageLen >= p.max) || (p.pageBytes > 0 && p.size >= p.pageBytes) {
+		if p.err = p.flushPage(); p.err != nil {
+			return p.err
+		}
+	}
+
+	if p.rowGroupBytes > 0 && p.spill.Size()+int64(float64(p.bytes)*p.ratio) >= int64(p.rowGroupBytes) {
+		p.err = p.Write()
+	} else if p.maxBuffered > 0 && p.spill.Buffered()+int64(p.bytes) >= int64(p.maxBuffered) {
+		p.err = p.Write()
+	}
+	return p.err
+}
+
+// AddNoError is Add without the returned error, which is how Add used to
+// work.  A rejected record is dropped and any other error is returned by
+// the next call to Write or Close.
+//
+// Deprecated: use Add.
+func (p *Writer[T]) AddNoError(rec T) {
+	p.Add(rec)
+}
+
+func (p *Writer[T]) validate(rec T) error {
+	for _, v := range p.funcs {
+		if err := v(rec); err != nil {
+			return &RecordError{Err: err}
+		}
+	}
+
+	if p.checks == (Checks{}) {
+		return nil
+	}
+
+	for _, f := range p.fields {
+		if err := f.Validate(rec, p.checks); err != nil {
+			return err
+		}
+	}
+	return nil
+}